@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/env"
+)
+
+// Kinds of issues found when checking an environment against an example file.
+const (
+	checkMissing = "missing"
+	checkExtra   = "extra"
+	checkEmpty   = "empty"
+)
+
+// checkIssue describes a variable which does not match the example file.
+type checkIssue struct {
+	Kind       string `json:"kind" xml:"kind,attr"`
+	Name       string `json:"name" xml:"name,attr"`
+	Suggestion string `json:"suggestion,omitempty" xml:"suggestion,attr,omitempty"`
+}
+
+// checkReport describes the result of checking an environment against an example file.
+type checkReport struct {
+	XMLName xml.Name     `json:"-" xml:"check"`
+	Example string       `json:"example" xml:"example,attr"`
+	Missing int          `json:"missing" xml:"missing,attr"`
+	Extra   int          `json:"extra" xml:"extra,attr"`
+	Empty   int          `json:"empty" xml:"empty,attr"`
+	Issues  []checkIssue `json:"issues" xml:"issue"`
+}
+
+// failed reports whether the environment misses variables or has empty values.
+func (r *checkReport) failed() bool {
+	return r.Missing > 0 || r.Empty > 0
+}
+
+// Text returns the report as a table.
+func (r *checkReport) Text() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	for _, issue := range r.Issues {
+		hint := ""
+		if issue.Suggestion != "" {
+			hint = "did you mean " + issue.Suggestion + "?"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", issue.Kind, issue.Name, hint)
+	}
+	_ = tw.Flush()
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n") {
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, fmt.Sprintf("%d missing, %d extra and %d empty variables compared to '%s'", r.Missing, r.Extra, r.Empty, r.Example))
+	return strings.Join(lines, "\n")
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// suggest returns the candidate closest to the given name if it looks like a typo of it.
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		d := levenshtein(strings.ToUpper(name), strings.ToUpper(c))
+		if d > 2 || d*3 > len(name) {
+			continue
+		}
+		if best == "" || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// checkExample compares the variables against the example ones.
+// The loaded variables are reported when not part of the example while the inherited ones are only used to find the missing ones.
+func checkExample(examplePath string, example, loaded, inherited env.Map) *checkReport {
+	r := &checkReport{Example: examplePath, Issues: []checkIssue{}}
+
+	var missing, extra []string
+	for name := range example {
+		value, ok := loaded[name]
+		if !ok {
+			value, ok = inherited[name]
+		}
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		if value == "" {
+			r.Issues = append(r.Issues, checkIssue{Kind: checkEmpty, Name: name})
+			r.Empty++
+		}
+	}
+	for name := range loaded {
+		if _, ok := example[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+
+	for _, name := range missing {
+		r.Issues = append(r.Issues, checkIssue{Kind: checkMissing, Name: name, Suggestion: suggest(name, extra)})
+	}
+	for _, name := range extra {
+		r.Issues = append(r.Issues, checkIssue{Kind: checkExtra, Name: name, Suggestion: suggest(name, missing)})
+	}
+	r.Missing, r.Extra = len(missing), len(extra)
+
+	kinds := map[string]int{checkMissing: 0, checkEmpty: 1, checkExtra: 2}
+	sort.Slice(r.Issues, func(i, j int) bool {
+		a, b := r.Issues[i], r.Issues[j]
+		if a.Kind != b.Kind {
+			return kinds[a.Kind] < kinds[b.Kind]
+		}
+		return a.Name < b.Name
+	})
+	return r
+}
+
+// parseEnvFile parses the variables of the given env file.
+func parseEnvFile(e *environ, filePath string) (env.Map, error) {
+	envf, err := e.openFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer envf.Close()
+	return envf.Parse()
+}
+
+// checkHandler compares the loaded environment against an example file.
+func checkHandler(ctx *app.CmdContext) error {
+	// example option
+	exampleF, err := ctx.Flags.String("example")
+	if err != nil {
+		return err
+	}
+	examplePath := exampleF.Value()
+
+	// process-env option
+	processEnvF, err := ctx.Flags.Bool("process-env")
+	if err != nil {
+		return err
+	}
+	processEnv, err := processEnvF.Value()
+	if err != nil {
+		return err
+	}
+
+	// output option
+	outputF, err := ctx.Flags.String("output")
+	if err != nil {
+		return err
+	}
+
+	environ, err := newEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	example, err := env.FromPath(examplePath)
+	if err != nil {
+		return err
+	}
+	defer example.Close()
+	exampleVars, err := example.Parse()
+	if err != nil {
+		return fmt.Errorf("error: cannot parse example file '%s'.\n%v", examplePath, err)
+	}
+
+	if environ.stdin {
+		piped, err := stdinPiped()
+		if err != nil {
+			return err
+		}
+		// NOTE: stdin is not piped so fallback to the env file
+		environ.stdin = piped
+	}
+
+	loaded := env.Map{}
+	if environ.stdin {
+		if loaded, err = env.FromReader(os.Stdin).Parse(); err != nil {
+			return fmt.Errorf("error: cannot read env from stdin.\n%v", err)
+		}
+	} else if !environ.noFile {
+		if loaded, err = parseEnvFile(environ, environ.filePath); err != nil {
+			return err
+		}
+	}
+
+	inherited := env.Map{}
+	if processEnv {
+		inherited = environ.baseEnv.Map()
+	}
+
+	report := checkExample(examplePath, exampleVars, loaded, inherited)
+	if err := printOutput(outputF.Value(), report.Text(), report); err != nil {
+		return err
+	}
+	if report.failed() {
+		return fmt.Errorf("error: environment does not match example file '%s'", examplePath)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_levenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "PORT", b: "", expected: 4},
+		{a: "PORT", b: "PORT", expected: 0},
+		{a: "DATABASE_URL", b: "DATABSE_URL", expected: 1},
+		{a: "API_KEY", b: "API_KYE", expected: 2},
+		{a: "kitten", b: "sitting", expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, levenshtein(tt.a, tt.b))
+			assert.Equal(t, tt.expected, levenshtein(tt.b, tt.a))
+		})
+	}
+}
+
+func Test_suggest(t *testing.T) {
+	candidates := []string{"DATABSE_URL", "api_key", "HOST"}
+
+	assert.Equal(t, "DATABSE_URL", suggest("DATABASE_URL", candidates))
+	assert.Equal(t, "api_key", suggest("API_KEY", candidates))
+	assert.Equal(t, "", suggest("PORT", candidates))
+	assert.Equal(t, "", suggest("HOSTNAME", candidates))
+	assert.Equal(t, "", suggest("PORT", nil))
+}
+
+func Test_checkExample(t *testing.T) {
+	example := env.Map{"DATABASE_URL": "", "API_KEY": "", "PORT": "", "HOME": ""}
+
+	tests := []struct {
+		name         string
+		loaded       env.Map
+		inherited    env.Map
+		expected     []checkIssue
+		expectedText string
+		failed       bool
+	}{
+		{
+			name:         "should report no issues for a matching environment",
+			loaded:       env.Map{"DATABASE_URL": "postgres://", "API_KEY": "x", "PORT": "80", "HOME": "/root"},
+			expected:     []checkIssue{},
+			expectedText: "0 missing, 0 extra and 0 empty variables compared to '.env.example'",
+		},
+		{
+			name:      "should report missing, empty and extra variables with suggestions",
+			loaded:    env.Map{"DATABSE_URL": "postgres://", "API_KEY": "", "PORT": "80", "DEBUG": "true"},
+			inherited: env.Map{"HOME": "/root", "PATH": "/bin"},
+			expected: []checkIssue{
+				{Kind: checkMissing, Name: "DATABASE_URL", Suggestion: "DATABSE_URL"},
+				{Kind: checkEmpty, Name: "API_KEY"},
+				{Kind: checkExtra, Name: "DATABSE_URL", Suggestion: "DATABASE_URL"},
+				{Kind: checkExtra, Name: "DEBUG"},
+			},
+			expectedText: "missing   DATABASE_URL   did you mean DATABSE_URL?\n" +
+				"empty     API_KEY\n" +
+				"extra     DATABSE_URL    did you mean DATABASE_URL?\n" +
+				"extra     DEBUG\n" +
+				"1 missing, 2 extra and 1 empty variables compared to '.env.example'",
+			failed: true,
+		},
+		{
+			name:   "should only report extra variables without failing",
+			loaded: env.Map{"DATABASE_URL": "postgres://", "API_KEY": "x", "PORT": "80", "HOME": "/root", "DEBUG": "1"},
+			expected: []checkIssue{
+				{Kind: checkExtra, Name: "DEBUG"},
+			},
+			expectedText: "extra   DEBUG\n" +
+				"0 missing, 1 extra and 0 empty variables compared to '.env.example'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := checkExample(".env.example", example, tt.loaded, tt.inherited)
+			assert.Equal(t, tt.expected, report.Issues)
+			assert.Equal(t, tt.expectedText, report.Text())
+			assert.Equal(t, tt.failed, report.failed())
+		})
+	}
+}
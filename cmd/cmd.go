@@ -22,6 +22,7 @@ func Execute(args []string) error {
 	ap.BuildTime = buildTime
 	ap.BuildCommit = buildCommit
 	ap.Flags = Flags
+	ap.Commands = Commands
 	ap.Handler = appHandler
 
 	return handler.New(ap).Run(args)
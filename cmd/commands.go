@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"github.com/joseluisq/cline/app"
+	"github.com/joseluisq/cline/flag"
+)
+
+var Commands = []app.Cmd{
+	{
+		Name:    "start",
+		Summary: "Run every Procfile process sharing the loaded environment",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "procfile",
+				Aliases: []string{"p"},
+				Value:   "Procfile",
+				Summary: "Procfile path containing the processes to run",
+			},
+			flag.FlagInt{
+				Name:    "port",
+				Value:   procDefaultPort,
+				Summary: "Base port assigned to the first process as PORT and incremented by 100 for the next ones",
+			},
+		},
+		Handler: startHandler,
+	},
+	{
+		Name:    "shell",
+		Summary: "Launch an interactive shell using the loaded environment",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "shell",
+				Aliases: []string{"s"},
+				Summary: "Shell executable to launch instead of $SHELL",
+			},
+			flag.FlagBool{
+				Name:    "no-prompt",
+				Value:   false,
+				Summary: "Do not prefix the shell prompt (PS1) with (enve)",
+			},
+		},
+		Handler: shellHandler,
+	},
+	{
+		Name:    "hook",
+		Summary: "Print the hook script loading env files on directory change for bash, zsh or fish",
+		Handler: hookHandler,
+	},
+	{
+		Name:    "export",
+		Summary: "Print the shell commands loading the env file of the current directory (used by hook)",
+		Handler: exportHandler,
+	},
+	{
+		Name:    "allow",
+		Summary: "Trust the given env files (or the --file one) with their current content",
+		Handler: allowHandler,
+	},
+	{
+		Name:    "deny",
+		Summary: "Remove the trust of the given env files (or the --file one)",
+		Handler: denyHandler,
+	},
+	{
+		Name:    "check",
+		Summary: "Check the loaded env file against an example file reporting missing, extra and empty variables",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "example",
+				Aliases: []string{"e"},
+				Value:   ".env.example",
+				Summary: "Example file containing the expected variables",
+			},
+			flag.FlagBool{
+				Name:    "process-env",
+				Value:   false,
+				Summary: "Consider the variables of the process environment when looking for missing ones",
+			},
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Summary: "Output the report using text, json or xml format",
+			},
+		},
+		Handler: checkHandler,
+	},
+	{
+		Name:    "lint",
+		Summary: "Check the given env files (or the --file one) for common problems",
+		Flags: []flag.Flag{
+			flag.FlagStringSlice{
+				Name:    "disable",
+				Aliases: []string{"d"},
+				Summary: "Comma-separated lint rules to disable",
+			},
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Summary: "Output the findings using text, json, xml or sarif format",
+			},
+		},
+		Handler: lintHandler,
+	},
+	{
+		Name:    "fmt",
+		Summary: "Format the given env files (or the --file one) keeping comments and ordering",
+		Flags: []flag.Flag{
+			flag.FlagBool{
+				Name:    "write",
+				Aliases: []string{"w"},
+				Value:   false,
+				Summary: "Write the result to the files instead of printing it",
+			},
+			flag.FlagBool{
+				Name:    "check",
+				Value:   false,
+				Summary: "Print the files which are not formatted and exit with a non-zero code if any",
+			},
+			flag.FlagBool{
+				Name:    "sort",
+				Aliases: []string{"s"},
+				Value:   false,
+				Summary: "Sort the variables alphabetically within blocks separated by blank lines",
+			},
+		},
+		Handler: fmtHandler,
+	},
+	{
+		Name:    "set",
+		Summary: "Set the given KEY=VALUE variables in an env file keeping its formatting",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Summary: "Env file to modify (defaults to the application --file one)",
+			},
+		},
+		Handler: setHandler,
+	},
+	{
+		Name:    "get",
+		Summary: "Print the value of a variable loaded from an env file",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Summary: "Env file to read (defaults to the application --file one)",
+			},
+		},
+		Handler: getHandler,
+	},
+	{
+		Name:    "unset",
+		Summary: "Remove the given variables from an env file keeping its formatting",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Summary: "Env file to modify (defaults to the application --file one)",
+			},
+		},
+		Handler: unsetHandler,
+	},
+	{
+		Name:    "diff",
+		Summary: "Compare two env files (or the process environment and an env file) reporting added, removed and changed variables",
+		Flags: []flag.Flag{
+			flag.FlagBool{
+				Name:    "current",
+				Value:   false,
+				Summary: "Compare the process environment against the variables of the given env file",
+			},
+			flag.FlagBool{
+				Name:    "mask",
+				Aliases: []string{"m"},
+				Value:   false,
+				Summary: "Mask the values of the differences",
+			},
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "text",
+				Summary: "Output the differences using text, json, xml or patch format",
+			},
+		},
+		Handler: diffHandler,
+	},
+	{
+		Name:    "merge",
+		Summary: "Merge the given env files into the first one keeping its comments and formatting",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Summary: "Write the merged env file to the given path instead of printing it",
+			},
+			flag.FlagString{
+				Name:    "prefer",
+				Aliases: []string{"p"},
+				Value:   "right",
+				Summary: "Resolve variables with different values using left, right or error-on-conflict",
+			},
+		},
+		Handler: mergeHandler,
+	},
+	{
+		Name:    "convert",
+		Summary: "Convert the variables of the given file (or stdin) between the dotenv, json and xml formats",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "from",
+				Summary: "Input format, either dotenv, json or xml",
+			},
+			flag.FlagString{
+				Name:    "to",
+				Summary: "Output format, either dotenv, json or xml",
+			},
+		},
+		Handler: convertHandler,
+	},
+	{
+		Name:    "encrypt",
+		Summary: "Encrypt the given env files (or the --file one) using a passphrase or age recipients",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Summary: "Write the encrypted file to the given path instead of replacing it",
+			},
+			flag.FlagBool{
+				Name:    "stdout",
+				Value:   false,
+				Summary: "Print the encrypted file instead of replacing it",
+			},
+			flag.FlagBool{
+				Name:    "values-only",
+				Value:   false,
+				Summary: "Only encrypt the values keeping variable names and comments readable",
+			},
+			flag.FlagStringSlice{
+				Name:    "recipient",
+				Aliases: []string{"r"},
+				Summary: "Encrypt as an age file for comma-separated X25519 recipients (age1...) instead of a passphrase",
+			},
+		},
+		Handler: encryptHandler,
+	},
+	{
+		Name:    "decrypt",
+		Summary: "Decrypt the given env files (or the --file one) using a passphrase or the ENVE_IDENTITY identity",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Summary: "Write the decrypted file to the given path instead of replacing it",
+			},
+			flag.FlagBool{
+				Name:    "stdout",
+				Value:   false,
+				Summary: "Print the decrypted file instead of replacing it",
+			},
+		},
+		Handler: decryptHandler,
+	},
+	{
+		Name:    "recipients",
+		Summary: "List, add or remove the recipients of an env file encrypted for recipients",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Summary: "Encrypted env file to modify (defaults to the application --file one)",
+			},
+		},
+		Handler: recipientsHandler,
+	},
+	{
+		Name:    "rekey",
+		Summary: "Encrypt the given env files (or the ones found in the given directories) again using a new passphrase or recipients",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "new-key-file",
+				Summary: "Read the new passphrase from a file instead of the ENVE_NEW_KEY variable or a prompt",
+			},
+			flag.FlagStringSlice{
+				Name:    "recipient",
+				Aliases: []string{"r"},
+				Summary: "Encrypt for comma-separated age X25519 recipients (age1...) instead of the current ones or a passphrase",
+			},
+			flag.FlagBool{
+				Name:    "dry-run",
+				Value:   false,
+				Summary: "Only verify that the current key decrypts the files, printing them without any change",
+			},
+		},
+		Handler: rekeyHandler,
+	},
+	{
+		Name:    "sign",
+		Summary: "Write the detached Ed25519 signatures (.sig) of the given env files (or the --file one)",
+		Flags: []flag.Flag{
+			flag.FlagString{
+				Name:    "key",
+				Aliases: []string{"k"},
+				Summary: "PEM encoded Ed25519 private key file used to sign",
+			},
+		},
+		Handler: signHandler,
+	},
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// decodeDotenv returns the variables of an env file in declaration order.
+func decodeDotenv(buf []byte) (env.Slice, error) {
+	vars, err := env.FromReader(bytes.NewReader(buf)).Parse()
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: the parser returns a map so the order is taken from the syntax tree
+	seen := map[string]bool{}
+	var keys []string
+	for _, n := range dotenv.ParseBytes(buf).Variables() {
+		if _, ok := vars[n.Key]; ok && !seen[n.Key] {
+			seen[n.Key] = true
+			keys = append(keys, n.Key)
+		}
+	}
+	var rest []string
+	for k := range vars {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+
+	environ := env.Slice{}
+	for _, k := range append(keys, rest...) {
+		environ = append(environ, k+"="+vars[k])
+	}
+	return environ, nil
+}
+
+// decodeJSON returns the variables of either the `enve` JSON output or a flat JSON object in order.
+func decodeJSON(buf []byte) (env.Slice, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+	if raw, ok := fields["environment"]; ok && len(fields) == 1 && bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		var environ env.Environment
+		if err := json.Unmarshal(buf, &environ); err != nil {
+			return nil, err
+		}
+		return environ.Slice(), nil
+	}
+
+	// NOTE: decode the object tokens to keep the order of the keys
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	environ := env.Slice{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case string:
+			environ = append(environ, key+"="+v)
+		case json.Number, bool:
+			environ = append(environ, fmt.Sprintf("%s=%v", key, v))
+		case nil:
+			environ = append(environ, key+"=")
+		default:
+			return nil, fmt.Errorf("value of '%s' is not a string, number or boolean", key)
+		}
+	}
+	return environ, nil
+}
+
+// decodeXML returns the variables of the `enve` XML output in order.
+func decodeXML(buf []byte) (env.Slice, error) {
+	var environ env.Environment
+	if err := xml.Unmarshal(buf, &environ); err != nil {
+		return nil, err
+	}
+	return environ.Slice(), nil
+}
+
+// encodeDotenv returns the variables as an env file quoting their values when needed.
+func encodeDotenv(vars env.Slice) ([]byte, error) {
+	var b bytes.Buffer
+	for _, v := range vars.Environ().Env {
+		if !dotenv.ValidName(v.Name) {
+			return nil, fmt.Errorf("'%s' is not a valid POSIX variable name", v.Name)
+		}
+		value, err := dotenv.Quote(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot write variable '%s'.\n%v", v.Name, err)
+		}
+		b.WriteString(v.Name + "=" + value + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+// convertHandler converts the variables of a file (or stdin) between the dotenv, json and xml formats.
+func convertHandler(ctx *app.CmdContext) error {
+	// from option
+	fromF, err := ctx.Flags.String("from")
+	if err != nil {
+		return err
+	}
+	if !fromF.IsProvided() {
+		return fmt.Errorf("error: input format was not provided, use the '--from' flag")
+	}
+	from := fromF.Value()
+	decoders := map[string]func([]byte) (env.Slice, error){
+		"dotenv": decodeDotenv,
+		"json":   decodeJSON,
+		"xml":    decodeXML,
+	}
+	decode, ok := decoders[from]
+	if !ok {
+		return fmt.Errorf("error: input format '%s' is not supported, use dotenv, json or xml", from)
+	}
+
+	// to option
+	toF, err := ctx.Flags.String("to")
+	if err != nil {
+		return err
+	}
+	if !toF.IsProvided() {
+		return fmt.Errorf("error: output format was not provided, use the '--to' flag")
+	}
+	to := toF.Value()
+	encoders := map[string]func(env.Slice) ([]byte, error){
+		"dotenv": encodeDotenv,
+		"json":   env.Slice.JSON,
+		"xml":    env.Slice.XML,
+	}
+	encode, ok := encoders[to]
+	if !ok {
+		return fmt.Errorf("error: output format '%s' is not supported, use dotenv, json or xml", to)
+	}
+
+	if len(ctx.TailArgs) > 1 {
+		return fmt.Errorf("error: a single input file is required, use 'enve convert [FILE]'")
+	}
+	input := "stdin"
+	var buf []byte
+	if len(ctx.TailArgs) == 0 || ctx.TailArgs[0] == "-" {
+		if buf, err = io.ReadAll(os.Stdin); err != nil {
+			return fmt.Errorf("error: cannot read from stdin.\n%v", err)
+		}
+	} else {
+		input = ctx.TailArgs[0]
+		if err := fs.FileExists(input); err != nil {
+			return err
+		}
+		if buf, err = os.ReadFile(input); err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", input, err)
+		}
+	}
+
+	vars, err := decode(buf)
+	if err != nil {
+		return fmt.Errorf("error: cannot parse %s input from %s.\n%v", from, input, err)
+	}
+	out, err := encode(vars)
+	if err != nil {
+		return fmt.Errorf("error: cannot convert %s input from %s to %s.\n%v", from, input, to, err)
+	}
+	switch to {
+	case "xml":
+		fmt.Println("<?xml version=\"1.0\" encoding=\"UTF-8\"?>" + string(out))
+	case "json":
+		fmt.Println(string(out))
+	default:
+		fmt.Print(string(out))
+	}
+	return nil
+}
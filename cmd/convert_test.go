@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_decodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    env.Slice
+		expectedErr string
+	}{
+		{
+			name:     "should decode the enve json output",
+			input:    `{"environment":[{"name":"B","value":"2"},{"name":"A","value":"1"}]}`,
+			expected: env.Slice{"B=2", "A=1"},
+		},
+		{
+			name:     "should decode a flat object keeping the order of the keys",
+			input:    `{"B": "x", "A": 1.50, "D": false, "C": null}`,
+			expected: env.Slice{"B=x", "A=1.50", "D=false", "C="},
+		},
+		{
+			name:     "should decode a flat object with an environment string",
+			input:    `{"environment": "production"}`,
+			expected: env.Slice{"environment=production"},
+		},
+		{
+			name:        "should return an error for nested values",
+			input:       `{"A": [1]}`,
+			expectedErr: "value of 'A' is not a string, number or boolean",
+		},
+		{
+			name:        "should return an error for non object values",
+			input:       `["A"]`,
+			expectedErr: "cannot unmarshal array",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, err := decodeJSON([]byte(tt.input))
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, vars)
+		})
+	}
+}
+
+func Test_convertRoundTrip(t *testing.T) {
+	vars := env.Slice{"B=two words", "A=$HOME \"quoted\"\nline", "C=it's \"x\""}
+
+	buf, err := encodeDotenv(vars)
+	assert.NoError(t, err)
+	decoded, err := decodeDotenv(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, vars, decoded)
+
+	buf, err = vars.XML()
+	assert.NoError(t, err)
+	decoded, err = decodeXML(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, vars, decoded)
+
+	_, err = encodeDotenv(env.Slice{"1A=1"})
+	assert.EqualError(t, err, "'1A' is not a valid POSIX variable name")
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/cline/app"
+	"github.com/joseluisq/cline/flag"
+	"golang.org/x/term"
+
+	"github.com/joseluisq/enve/crypt"
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// keySource resolves the passphrase of encrypted env files
+// from a key file, the ENVE_KEY variable or an interactive prompt in that order.
+type keySource struct {
+	keyFile string
+	// Flag and variable providing the passphrase, used in error messages.
+	keyFileFlag string
+	envVar      string
+	// Kind of passphrase asked by the prompt.
+	label string
+	// Passphrase entered via prompt, reused for the next files.
+	prompted []byte
+}
+
+// newKeySource creates a key source from the `--key-file` flag.
+func newKeySource(flags *flag.FlagValues) (*keySource, error) {
+	// key-file option
+	keyFileF, err := flags.String("key-file")
+	if err != nil {
+		return nil, err
+	}
+	return &keySource{
+		keyFile:     keyFileF.Value(),
+		keyFileFlag: "--key-file",
+		envVar:      env.KeyEnvVar,
+		label:       "passphrase",
+	}, nil
+}
+
+// passphrase returns the passphrase for the given file, asking twice when confirm is enabled and prompting.
+func (k *keySource) passphrase(filePath string, confirm bool) ([]byte, error) {
+	if k.keyFile != "" {
+		if err := fs.FileExists(k.keyFile); err != nil {
+			return nil, err
+		}
+		buf, err := os.ReadFile(k.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read key file '%s'.\n%v", k.keyFile, err)
+		}
+		buf = bytes.TrimRight(buf, "\r\n")
+		if len(buf) == 0 {
+			return nil, fmt.Errorf("error: key file '%s' is empty", k.keyFile)
+		}
+		return buf, nil
+	}
+	if key := os.Getenv(k.envVar); key != "" {
+		return []byte(key), nil
+	}
+	if k.prompted != nil {
+		return k.prompted, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("error: no %s for file '%s', provide it via %s or %s", k.label, filePath, k.envVar, k.keyFileFlag)
+	}
+	fmt.Fprintf(os.Stderr, "enve: enter the %s of '%s': ", k.label, filePath)
+	key, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot read the passphrase.\n%v", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("error: %s was empty", k.label)
+	}
+	if confirm {
+		fmt.Fprintf(os.Stderr, "enve: confirm the %s: ", k.label)
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read the passphrase.\n%v", err)
+		}
+		if !bytes.Equal(key, again) {
+			return nil, fmt.Errorf("error: passphrases do not match")
+		}
+	}
+	k.prompted = key
+	return key, nil
+}
+
+// isEncryptedFile reports whether the env file content is encrypted either as a whole or only its values.
+func isEncryptedFile(buf []byte) bool {
+	return crypt.IsEncrypted(buf) || crypt.HasEncryptedValues(buf)
+}
+
+// cryptOutput describes where the encrypt and decrypt commands write their results.
+type cryptOutput struct {
+	path   string
+	stdout bool
+}
+
+// cryptFiles returns the files of the encrypt and decrypt commands along with their output.
+func cryptFiles(ctx *app.CmdContext) ([]string, *cryptOutput, error) {
+	// output option
+	outputF, err := ctx.Flags.String("output")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// stdout option
+	stdoutF, err := ctx.Flags.Bool("stdout")
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := stdoutF.Value()
+	if err != nil {
+		return nil, nil, err
+	}
+	if stdout && outputF.IsProvided() {
+		return nil, nil, fmt.Errorf("error: flag '--stdout' cannot be used along with '--output'")
+	}
+
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if outputF.IsProvided() && len(files) > 1 {
+		return nil, nil, fmt.Errorf("error: flag '--output' cannot be used along with several files")
+	}
+	return files, &cryptOutput{path: outputF.Value(), stdout: stdout}, nil
+}
+
+// write writes the result of a file to stdout, the output path or the file itself.
+func (o *cryptOutput) write(filePath string, data []byte) error {
+	switch {
+	case o.stdout:
+		_, err := os.Stdout.Write(data)
+		return err
+	case o.path != "":
+		return fs.WriteFileAtomic(o.path, data, 0600)
+	default:
+		return fs.WriteFileAtomic(filePath, data, 0600)
+	}
+}
+
+// encryptHandler encrypts the given env files (or the one of the `--file` flag) in place.
+func encryptHandler(ctx *app.CmdContext) error {
+	// values-only option
+	valuesOnlyF, err := ctx.Flags.Bool("values-only")
+	if err != nil {
+		return err
+	}
+	valuesOnly, err := valuesOnlyF.Value()
+	if err != nil {
+		return err
+	}
+
+	// recipient option
+	recipientF, err := ctx.Flags.StringSlice("recipient")
+	if err != nil {
+		return err
+	}
+	var recipients []string
+	if recipientF.IsProvided() {
+		recipients = recipientF.Value()
+		if valuesOnly {
+			return fmt.Errorf("error: flag '--recipient' cannot be used along with '--values-only'")
+		}
+	}
+
+	files, output, err := cryptFiles(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := newKeySource(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", file, err)
+		}
+		if isEncryptedFile(buf) {
+			return fmt.Errorf("error: file '%s' is already encrypted", file)
+		}
+		encrypted, err := encryptFile(file, buf, keys, recipients, valuesOnly)
+		if err != nil {
+			return err
+		}
+		if err := output.write(file, encrypted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decryptHandler decrypts the given env files (or the one of the `--file` flag) in place.
+func decryptHandler(ctx *app.CmdContext) error {
+	files, output, err := cryptFiles(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := newKeySource(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", file, err)
+		}
+		if !isEncryptedFile(buf) {
+			return fmt.Errorf("error: file '%s' is not encrypted", file)
+		}
+		decrypted, err := decryptFile(file, buf, keys)
+		if err != nil {
+			return err
+		}
+		if err := output.write(file, decrypted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encryptFile returns the encrypted content of an env file
+// for the given recipients if any or using a passphrase otherwise.
+func encryptFile(filePath string, buf []byte, keys *keySource, recipients []string, valuesOnly bool) ([]byte, error) {
+	var encrypted []byte
+	if recipients != nil {
+		encrypted, err := crypt.EncryptFor(buf, recipients)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot encrypt file '%s'.\n%v", filePath, err)
+		}
+		return encrypted, nil
+	}
+	key, err := keys.passphrase(filePath, true)
+	if err != nil {
+		return nil, err
+	}
+	if valuesOnly {
+		encrypted, err = crypt.EncryptValues(buf, key)
+	} else {
+		encrypted, err = crypt.Encrypt(buf, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot encrypt file '%s'.\n%v", filePath, err)
+	}
+	return encrypted, nil
+}
+
+// decryptFile returns the plain content of an encrypted env file
+// using the identity of ENVE_IDENTITY for recipients or a passphrase otherwise.
+func decryptFile(filePath string, buf []byte, keys *keySource) ([]byte, error) {
+	var decrypted []byte
+	if crypt.HasRecipients(buf) {
+		identities, err := env.DecryptionIdentity(filePath)
+		if err != nil {
+			return nil, err
+		}
+		decrypted, err = crypt.DecryptWith(buf, identities)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot decrypt file '%s'.\n%v", filePath, err)
+		}
+		return decrypted, nil
+	}
+	key, err := keys.passphrase(filePath, false)
+	if err != nil {
+		return nil, err
+	}
+	if crypt.IsEncrypted(buf) {
+		decrypted, err = crypt.Decrypt(buf, key)
+	} else {
+		decrypted, err = crypt.DecryptValues(buf, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot decrypt file '%s'.\n%v", filePath, err)
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/env"
+)
+
+// diffMask replaces the values of the differences when masking is enabled.
+const diffMask = "****"
+
+// currentEnvName is the name of the process environment in diff reports.
+const currentEnvName = "environment"
+
+// diffChange is a variable added, removed or changed between two environments.
+type diffChange struct {
+	Kind     string  `json:"kind" xml:"kind,attr"`
+	Name     string  `json:"name" xml:"name,attr"`
+	OldValue *string `json:"old_value,omitempty" xml:"old,omitempty"`
+	NewValue *string `json:"new_value,omitempty" xml:"new,omitempty"`
+}
+
+// diffReport contains the differences between two environments ordered by variable name.
+type diffReport struct {
+	XMLName xml.Name     `json:"-" xml:"diff"`
+	From    string       `json:"from" xml:"from,attr"`
+	To      string       `json:"to" xml:"to,attr"`
+	Changes []diffChange `json:"changes" xml:"change"`
+}
+
+// diffEnv compares the variables of two environments.
+func diffEnv(from, to string, oldVars, newVars env.Map, mask bool) *diffReport {
+	value := func(v string) *string {
+		if mask {
+			v = diffMask
+		}
+		return &v
+	}
+
+	report := &diffReport{From: from, To: to, Changes: []diffChange{}}
+	for name, oldValue := range oldVars {
+		newValue, ok := newVars[name]
+		switch {
+		case !ok:
+			report.Changes = append(report.Changes, diffChange{Kind: "removed", Name: name, OldValue: value(oldValue)})
+		case newValue != oldValue:
+			report.Changes = append(report.Changes, diffChange{Kind: "changed", Name: name, OldValue: value(oldValue), NewValue: value(newValue)})
+		}
+	}
+	for name, newValue := range newVars {
+		if _, ok := oldVars[name]; !ok {
+			report.Changes = append(report.Changes, diffChange{Kind: "added", Name: name, NewValue: value(newValue)})
+		}
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Name < report.Changes[j].Name
+	})
+	return report
+}
+
+// diffValue returns a value as written in env files or Go quoted if it cannot be written.
+func diffValue(v string) string {
+	if quoted, err := dotenv.Quote(v); err == nil {
+		return quoted
+	}
+	return strconv.Quote(v)
+}
+
+// Text returns the differences in a unified diff like format.
+func (r *diffReport) Text() string {
+	lines := []string{"--- " + r.From, "+++ " + r.To}
+	for _, c := range r.Changes {
+		if c.OldValue != nil {
+			lines = append(lines, "-"+c.Name+"="+diffValue(*c.OldValue))
+		}
+		if c.NewValue != nil {
+			lines = append(lines, "+"+c.Name+"="+diffValue(*c.NewValue))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Patch returns the `enve set` and `enve unset` commands turning the old environment into the new one.
+// The commands target the old env file unless it is the process environment.
+func (r *diffReport) Patch() string {
+	target := ""
+	if r.From != currentEnvName {
+		target = " -f " + shellQuote(r.From)
+	}
+	lines := []string{}
+	for _, c := range r.Changes {
+		if c.NewValue == nil {
+			lines = append(lines, "enve unset"+target+" "+shellQuote(c.Name))
+		} else {
+			lines = append(lines, "enve set"+target+" "+shellQuote(c.Name+"="+*c.NewValue))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffHandler compares two env files (or the process environment and an env file).
+// Like diff(1), it exits with status 1 when there are differences and 2 when the comparison fails.
+func diffHandler(ctx *app.CmdContext) error {
+	err := diffFiles(ctx)
+	var exitErr *ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return &ExitError{Code: 2, Err: err}
+	}
+	return err
+}
+
+// diffFiles compares the env files given by the command arguments printing their differences.
+func diffFiles(ctx *app.CmdContext) error {
+	// current option
+	currentF, err := ctx.Flags.Bool("current")
+	if err != nil {
+		return err
+	}
+	current, err := currentF.Value()
+	if err != nil {
+		return err
+	}
+
+	// mask option
+	maskF, err := ctx.Flags.Bool("mask")
+	if err != nil {
+		return err
+	}
+	mask, err := maskF.Value()
+	if err != nil {
+		return err
+	}
+
+	// output option
+	outputF, err := ctx.Flags.String("output")
+	if err != nil {
+		return err
+	}
+	output := outputF.Value()
+	if output == "patch" && mask {
+		return fmt.Errorf("error: flag '--mask' cannot be used along with the patch output")
+	}
+
+	files := ctx.TailArgs
+	if current && len(files) != 1 {
+		return fmt.Errorf("error: a single env file is required along with '--current', use 'enve diff --current FILE'")
+	}
+	if !current && len(files) != 2 {
+		return fmt.Errorf("error: two env files are required, use 'enve diff FILE FILE'")
+	}
+
+	environ, err := newEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	from := currentEnvName
+	oldVars := env.Map{}
+	if !current {
+		from, files = files[0], files[1:]
+		if oldVars, err = parseEnvFile(environ, from); err != nil {
+			return err
+		}
+	}
+	newVars, err := parseEnvFile(environ, files[0])
+	if err != nil {
+		return err
+	}
+	if current {
+		// NOTE: only the variables of the env file are compared to leave out the unrelated ones
+		baseVars := environ.baseEnv.Map()
+		for name := range newVars {
+			if value, ok := baseVars[name]; ok {
+				oldVars[name] = value
+			}
+		}
+	}
+
+	report := diffEnv(from, files[0], oldVars, newVars, mask)
+	switch {
+	case output == "patch":
+		if len(report.Changes) > 0 {
+			fmt.Println(report.Patch())
+		}
+	case output != "text" || len(report.Changes) > 0:
+		if err := printOutput(output, report.Text(), report); err != nil {
+			return err
+		}
+	}
+
+	if len(report.Changes) == 0 {
+		return nil
+	}
+	if output != "text" {
+		// NOTE: keep machine-readable outputs free of error messages
+		return &ExitError{Code: 1}
+	}
+	return &ExitError{
+		Code: 1,
+		Err:  fmt.Errorf("error: found %d differences between '%s' and '%s'", len(report.Changes), from, files[0]),
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_diffEnv(t *testing.T) {
+	oldVars := env.Map{"HOST": "localhost", "PORT": "8080", "DEBUG": "true"}
+	newVars := env.Map{"HOST": "example.com", "PORT": "8080", "NAME": "it's"}
+
+	report := diffEnv("a.env", "b.env", oldVars, newVars, false)
+	assert.Equal(t, []string{"removed", "changed", "added"}, []string{
+		report.Changes[0].Kind, report.Changes[1].Kind, report.Changes[2].Kind,
+	})
+	assert.Equal(t, "--- a.env\n+++ b.env\n-DEBUG=true\n-HOST=localhost\n+HOST=example.com\n+NAME=\"it's\"", report.Text())
+	assert.Equal(t, "enve unset -f a.env DEBUG\nenve set -f a.env HOST=example.com\nenve set -f a.env 'NAME=it'\"'\"'s'", report.Patch())
+
+	masked := diffEnv(currentEnvName, "b.env", oldVars, newVars, true)
+	assert.Equal(t, "--- environment\n+++ b.env\n-DEBUG=****\n-HOST=****\n+HOST=****\n+NAME=****", masked.Text())
+	assert.Equal(t, "enve unset DEBUG\nenve set 'HOST=****'\nenve set 'NAME=****'", masked.Patch())
+
+	assert.Empty(t, diffEnv("a.env", "b.env", oldVars, oldVars, false).Changes)
+}
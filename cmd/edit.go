@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/fs"
+)
+
+// editFilePath returns the env file of the command `--file` flag or the application one.
+func editFilePath(ctx *app.CmdContext) (string, error) {
+	// file option
+	fileF, err := ctx.Flags.String("file")
+	if err != nil {
+		return "", err
+	}
+	if fileF.IsProvided() {
+		return fileF.Value(), nil
+	}
+	appFileF, err := ctx.AppContext.Flags().String("file")
+	if err != nil {
+		return "", err
+	}
+	return appFileF.Value(), nil
+}
+
+// editFile applies the given changes to an env file and writes it atomically keeping its file mode.
+// When create is enabled a missing file gets created.
+func editFile(filePath string, create bool, edit func(f *dotenv.File) error) error {
+	if !create {
+		if err := fs.FileExists(filePath); err != nil {
+			return err
+		}
+	}
+	buf, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	if isEncryptedFile(buf) {
+		return fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", filePath)
+	}
+	f := dotenv.ParseBytes(buf)
+	if err := edit(f); err != nil {
+		return err
+	}
+	return fs.WriteFileAtomic(filePath, f.Bytes(), 0644)
+}
+
+// setHandler sets the given `KEY=VALUE` variables in an env file.
+func setHandler(ctx *app.CmdContext) error {
+	filePath, err := editFilePath(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ctx.TailArgs) == 0 {
+		return fmt.Errorf("error: no variables to set, use 'enve set KEY=VALUE'")
+	}
+	for _, arg := range ctx.TailArgs {
+		if !strings.Contains(arg, "=") {
+			return fmt.Errorf("error: invalid variable '%s', use the KEY=VALUE format", arg)
+		}
+	}
+
+	return editFile(filePath, true, func(f *dotenv.File) error {
+		for _, arg := range ctx.TailArgs {
+			key, value, _ := strings.Cut(arg, "=")
+			if err := f.Set(key, value); err != nil {
+				return fmt.Errorf("error: cannot set variable '%s' in file '%s'.\n%v", key, filePath, err)
+			}
+		}
+		return nil
+	})
+}
+
+// unsetHandler removes the given variables from an env file.
+func unsetHandler(ctx *app.CmdContext) error {
+	filePath, err := editFilePath(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ctx.TailArgs) == 0 {
+		return fmt.Errorf("error: no variables to unset, use 'enve unset KEY'")
+	}
+
+	return editFile(filePath, false, func(f *dotenv.File) error {
+		for _, key := range ctx.TailArgs {
+			f.Unset(key)
+		}
+		return nil
+	})
+}
+
+// getHandler prints the value of a variable loaded from an env file.
+func getHandler(ctx *app.CmdContext) error {
+	filePath, err := editFilePath(ctx)
+	if err != nil {
+		return err
+	}
+	if len(ctx.TailArgs) != 1 {
+		return fmt.Errorf("error: a single variable name is required, use 'enve get KEY'")
+	}
+	key := ctx.TailArgs[0]
+
+	environ, err := newEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+	vars, err := parseEnvFile(environ, filePath)
+	if err != nil {
+		return err
+	}
+	value, ok := vars[key]
+	if !ok {
+		return fmt.Errorf("error: variable '%s' is not set in file '%s'", key, filePath)
+	}
+	fmt.Println(value)
+	return nil
+}
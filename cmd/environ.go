@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joseluisq/cline/flag"
+
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+	"github.com/joseluisq/enve/schema"
+	"github.com/joseluisq/enve/signature"
+)
+
+// environ represents the environment built from the application flags.
+type environ struct {
+	// Final environment variables.
+	vars env.Slice
+	// Environment inherited before loading any variables.
+	baseEnv env.Slice
+
+	filePath     string
+	chdirPath    string
+	keepPatterns []string
+	newEnv       bool
+	ignoreEnv    bool
+	noFile       bool
+	stdin        bool
+	overwrite    bool
+	requireTrust bool
+	verifyKey    ed25519.PublicKey
+	schemaPath   string
+	schema       schema.Schema
+	// Resolver of the secret references of the loaded variables.
+	secrets *env.SecretResolver
+}
+
+// isolated reports whether the inherited environment gets replaced by the final variables.
+func (e *environ) isolated() bool {
+	return e.newEnv || e.ignoreEnv
+}
+
+// files returns the env file paths loaded into the environment if any.
+func (e *environ) files() []string {
+	if e.stdin || e.noFile || e.ignoreEnv {
+		return nil
+	}
+	return []string{e.filePath}
+}
+
+// reload parses the env file again building a fresh environment without modifying the current process one.
+// Secret references are resolved again so rotated secrets are picked up.
+func (e *environ) reload() (env.Slice, error) {
+	e.secrets.Reset()
+	return e.fromFile(e.filePath)
+}
+
+// fromFile builds a fresh environment using the given env file without modifying the current process one.
+func (e *environ) fromFile(filePath string) (env.Slice, error) {
+	envf, err := e.openFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer envf.Close()
+	vmap, err := e.parseVars(envf)
+	if err != nil {
+		return nil, err
+	}
+	base, overwrite := e.baseEnv, e.overwrite
+	if e.newEnv {
+		if base, err = e.baseEnv.Match(e.keepPatterns...); err != nil {
+			return nil, err
+		}
+		overwrite = true
+	}
+	return e.applySchema(base.Merge(vmap, overwrite))
+}
+
+// parseVars returns the variables of the given env file or reader resolving their secret references.
+func (e *environ) parseVars(r env.EnvReader) (env.Map, error) {
+	vmap, err := r.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.secrets.ResolveAll(vmap); err != nil {
+		return nil, err
+	}
+	return vmap, nil
+}
+
+// applySchema applies the schema defaults to the given variables making sure they are valid if a schema was provided.
+func (e *environ) applySchema(vars env.Slice) (env.Slice, error) {
+	if e.schema == nil {
+		return vars, nil
+	}
+	vars = vars.Merge(e.schema.Defaults(vars.Map()), false)
+	if violations := e.schema.Validate(vars.Map()); len(violations) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "error: environment does not match schema '%s' (%d violations):", e.schemaPath, len(violations))
+		for _, v := range violations {
+			b.WriteString("\n  " + v.String())
+		}
+		return nil, errors.New(b.String())
+	}
+	return vars, nil
+}
+
+// openFile opens the given env file making sure it is trusted and signed if required.
+func (e *environ) openFile(filePath string) (env.EnvFile, error) {
+	buf, err := e.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return env.FromBytes(filePath, buf)
+}
+
+// readFile reads the given env file making sure it is trusted and signed if required.
+// The file is read once so the checked content is the one which gets parsed.
+func (e *environ) readFile(filePath string) ([]byte, error) {
+	if err := fs.FileExists(filePath); err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	if e.requireTrust {
+		if err := checkTrust(filePath, buf); err != nil {
+			return nil, err
+		}
+	}
+	if e.verifyKey != nil {
+		if err := signature.VerifyFileContent(filePath, buf, e.verifyKey); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// loadEnviron builds the environment using the application flags
+// by loading variables from an env file or stdin.
+func loadEnviron(flags *flag.FlagValues) (*environ, error) {
+	e, err := newEnviron(flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// newEnviron creates an environment using the application flags
+// changing the current working directory if needed but without loading any variables yet.
+func newEnviron(flags *flag.FlagValues) (*environ, error) {
+	var e = &environ{baseEnv: env.Slice(os.Environ())}
+
+	// ignore-environment option
+	ignoreEnvF, err := flags.Bool("ignore-environment")
+	if err != nil {
+		return nil, err
+	}
+	e.ignoreEnv, err = ignoreEnvF.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	// no-file option
+	noFileF, err := flags.Bool("no-file")
+	if err != nil {
+		return nil, err
+	}
+	e.noFile, err = noFileF.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	// file option
+	file, err := flags.String("file")
+	if err != nil {
+		return nil, err
+	}
+	e.filePath = file.Value()
+
+	// key-file option
+	keys, err := newKeySource(flags)
+	if err != nil {
+		return nil, err
+	}
+	env.DecryptionKey = func(filePath string) ([]byte, error) {
+		return keys.passphrase(filePath, false)
+	}
+
+	// new-environment option
+	newEnvF, err := flags.Bool("new-environment")
+	if err != nil {
+		return nil, err
+	}
+	e.newEnv, err = newEnvF.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	// stdin option
+	stdinF, err := flags.Bool("stdin")
+	if err != nil {
+		return nil, err
+	}
+	e.stdin, err = stdinF.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	// overwrite option
+	overwriteF, err := flags.Bool("overwrite")
+	if err != nil {
+		return nil, err
+	}
+	e.overwrite, err = overwriteF.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep option
+	keepF, err := flags.StringSlice("keep")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range keepF.Value() {
+		if p != "" {
+			e.keepPatterns = append(e.keepPatterns, p)
+		}
+	}
+
+	// keep-defaults option
+	keepDefaultsF, err := flags.Bool("keep-defaults")
+	if err != nil {
+		return nil, err
+	}
+	keepDefaults, err := keepDefaultsF.Value()
+	if err != nil {
+		return nil, err
+	}
+	if keepDefaults {
+		e.keepPatterns = append(e.keepPatterns, defaultKeepVars...)
+	}
+
+	// require-trust option
+	requireTrustF, err := flags.Bool("require-trust")
+	if err != nil {
+		return nil, err
+	}
+	e.requireTrust, err = requireTrustF.Value()
+	if err != nil {
+		return nil, err
+	}
+	if e.requireTrust && e.stdin {
+		// NOTE: content read from stdin cannot be trusted by path and hash
+		return nil, fmt.Errorf("error: flag '--require-trust' cannot be used along with '--stdin'")
+	}
+
+	// allow-exec-refs option
+	allowExecRefsF, err := flags.Bool("allow-exec-refs")
+	if err != nil {
+		return nil, err
+	}
+	allowExecRefs, err := allowExecRefsF.Value()
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: env files of arbitrary sources must not run commands unless the user opted in
+	var execProvider env.SecretProvider = env.SecretProviderFunc(func(string) (string, error) {
+		return "", errors.New("exec references run shell commands, allow them via '--allow-exec-refs'")
+	})
+	if allowExecRefs {
+		execProvider = env.ExecProvider
+	}
+	e.secrets = env.NewSecretResolver(map[string]env.SecretProvider{"exec": execProvider})
+
+	// chdir option
+	chdir, err := flags.String("chdir")
+	if err != nil {
+		return nil, err
+	}
+	if chdir.IsProvided() {
+		e.chdirPath = chdir.Value()
+		if err := fs.DirExists(e.chdirPath); err != nil {
+			return nil, err
+		}
+		if err := os.Chdir(e.chdirPath); err != nil {
+			return nil, fmt.Errorf("error: cannot change directory to '%s'.\n%v", e.chdirPath, err)
+		}
+	}
+
+	// schema option
+	schemaF, err := flags.String("schema")
+	if err != nil {
+		return nil, err
+	}
+	if schemaF.IsProvided() {
+		e.schemaPath = schemaF.Value()
+		if e.schema, err = schema.FromPath(e.schemaPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// verify-with option
+	verifyWithF, err := flags.String("verify-with")
+	if err != nil {
+		return nil, err
+	}
+	if verifyWithF.IsProvided() {
+		if e.stdin {
+			return nil, fmt.Errorf("error: flag '--verify-with' cannot be used along with '--stdin'")
+		}
+		if e.verifyKey, err = readPublicKey(verifyWithF.Value()); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// stdinPiped checks if stdin is piped or redirected instead of being a terminal.
+func stdinPiped() (bool, error) {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false, fmt.Errorf("error: cannot read from stdin.\n%v", err)
+	}
+	return (fi.Mode() & os.ModeCharDevice) == 0, nil
+}
+
+// load loads the variables from an env file or stdin into the environment.
+func (e *environ) load() error {
+	var envVars env.Slice
+	var newEnv, ignoreEnv, overwrite = e.newEnv, e.ignoreEnv, e.overwrite
+
+	if e.stdin {
+		piped, err := stdinPiped()
+		if err != nil {
+			return err
+		}
+		if piped {
+			envr := env.FromReader(os.Stdin)
+
+			if ignoreEnv {
+				goto ContinueEnvProc
+			}
+
+			if newEnv {
+				vmap, err := e.parseVars(envr)
+				if err != nil {
+					return err
+				}
+				envVars = vmap.Array()
+			} else {
+				vmap, err := e.parseVars(envr)
+				if err != nil {
+					str := ""
+					if overwrite {
+						str = " (overwrite)"
+					}
+					return fmt.Errorf("error: cannot load env from stdin%s.\n%v", str, err)
+				}
+				vmap.Load(overwrite)
+				envVars = env.Slice(os.Environ())
+			}
+
+			goto ContinueEnvProc
+		}
+
+		// NOTE: stdin is not piped so fallback to the env file
+		e.stdin = false
+	}
+
+	if !ignoreEnv {
+		if e.noFile {
+			if newEnv || ignoreEnv {
+				goto ContinueEnvProc
+			}
+
+			envVars = env.Slice(os.Environ())
+			goto ContinueEnvProc
+		}
+
+		// .env file processing
+		envf, err := e.openFile(e.filePath)
+		if err != nil {
+			return err
+		}
+		defer envf.Close()
+
+		if newEnv {
+			vmap, err := e.parseVars(envf)
+			if err != nil {
+				return err
+			}
+			envVars = vmap.Array()
+		} else {
+			vmap, err := e.parseVars(envf)
+			if err != nil {
+				str := ""
+				if overwrite {
+					str = " (overwrite)"
+				}
+				return fmt.Errorf("error: cannot load env from file%s.\n%v", str, err)
+			}
+			vmap.Load(overwrite)
+
+			envVars = env.Slice(os.Environ())
+		}
+	}
+
+ContinueEnvProc:
+	// Whitelist inherited variables into the otherwise clean environment
+	if (newEnv || ignoreEnv) && len(e.keepPatterns) > 0 {
+		kept, err := e.baseEnv.Match(e.keepPatterns...)
+		if err != nil {
+			return fmt.Errorf("error: invalid keep pattern.\n%v", err)
+		}
+		// NOTE: the variables of the env file or stdin take precedence over the kept ones
+		envVars = kept.Merge(envVars.Map(), true)
+	}
+
+	vars, err := e.applySchema(envVars)
+	if err != nil {
+		return err
+	}
+	if !newEnv && !ignoreEnv && e.schema != nil {
+		// NOTE: the command inherits the process environment so defaults are set there as well
+		for k, v := range e.schema.Defaults(envVars.Map()) {
+			_ = os.Setenv(k, v)
+		}
+	}
+
+	e.vars = vars
+	return nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import "fmt"
+
+// ExitError represents an error which should terminate the application with a specific exit code.
+// Errors without a cause are not printed since their outcome was already reported.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -4,25 +4,36 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"os/exec"
+	"syscall"
 )
 
-// execCmd executes a command along with its env variables
-func execCmd(tailArgs []string, chdirPath string, newEnv bool, envVars []string) (err error) {
+// newCmd creates a command from the tail arguments which receives a SIGTERM signal when the context is done.
+func newCmd(ctx context.Context, tailArgs []string) (*exec.Cmd, error) {
 	cmdIn := tailArgs[0]
 	c, err := exec.LookPath(cmdIn)
 	if err != nil {
-		return fmt.Errorf("error: executable '%s' was not found.\n%v", cmdIn, err)
+		return nil, fmt.Errorf("error: executable '%s' was not found.\n%v", cmdIn, err)
 	}
-	cmd := exec.Command(c, tailArgs[1:]...)
-	cmd.Dir = chdirPath
-	if newEnv {
-		cmd.Env = envVars
+	cmd := exec.CommandContext(ctx, c, tailArgs[1:]...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
 	}
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	return cmd, nil
 }
+
+// shellCmd creates a command which runs a command line through the system shell.
+// The command runs in its own process group which receives a SIGTERM signal when the context is done.
+func shellCmd(ctx context.Context, cmdLine string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmdLine)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	return cmd
+}
+
+// defaultShell is the shell used when no one is provided or found in the environment.
+const defaultShell = "/bin/sh"
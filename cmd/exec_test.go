@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -72,6 +73,22 @@ func Test_execCmd(t *testing.T) {
 				"DB_PASSWORD=\n" +
 				"DB_ARGS=\n",
 		},
+		{
+			name:     "should execute command with an empty new environment",
+			tailArgs: []string{bashFile},
+			newEnv:   true,
+			expectedOutput: "" +
+				"DB_PROTOCOL=\n" +
+				"DB_HOST=\n" +
+				"DB_PORT=\n" +
+				"DB_DEFAULT_CHARACTER_SET=\n" +
+				"DB_EXPORT_GZIP=\n" +
+				"DB_EXPORT_FILE_PATH=\n" +
+				"DB_NAME=\n" +
+				"DB_USERNAME=\n" +
+				"DB_PASSWORD=\n" +
+				"DB_ARGS=\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,3 +121,72 @@ func Test_execCmd(t *testing.T) {
 		})
 	}
 }
+
+func Test_execCmdTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		tailArgs     []string
+		timeout      time.Duration
+		killAfter    time.Duration
+		expectedErr  error
+		expectedCode int
+		maxDuration  time.Duration
+	}{
+		{
+			name:     "should execute a command without timeout",
+			tailArgs: []string{"true"},
+		},
+		{
+			name:     "should execute a command finishing before the timeout",
+			tailArgs: []string{"true"},
+			timeout:  5 * time.Second,
+		},
+		{
+			name:         "should terminate a command when the timeout expires",
+			tailArgs:     []string{"sleep", "5"},
+			timeout:      100 * time.Millisecond,
+			expectedErr:  errors.New("error: command timed out after 100ms"),
+			expectedCode: timeoutExitCode,
+			maxDuration:  3 * time.Second,
+		},
+		{
+			name:         "should kill a command ignoring SIGTERM after the grace period",
+			tailArgs:     []string{"sh", "-c", "trap '' TERM; sleep 1; sleep 1"},
+			timeout:      100 * time.Millisecond,
+			killAfter:    100 * time.Millisecond,
+			expectedErr:  errors.New("error: command timed out after 100ms"),
+			expectedCode: timeoutExitCode,
+			maxDuration:  1500 * time.Millisecond,
+		},
+		{
+			name:        "should return the command error when it fails before the timeout",
+			tailArgs:    []string{"false"},
+			timeout:     5 * time.Second,
+			expectedErr: errors.New("exit status 1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := execCmdTimeout(tt.tailArgs, "", false, nil, tt.timeout, tt.killAfter)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err, "expected an error but got none")
+				assert.Equal(t, tt.expectedErr.Error(), err.Error(), "expected error message to match")
+			} else {
+				assert.NoError(t, err, "did not expect an error but got one")
+			}
+
+			if tt.expectedCode > 0 {
+				var exitErr *ExitError
+				assert.ErrorAs(t, err, &exitErr, "expected an exit error")
+				assert.Equal(t, tt.expectedCode, exitErr.Code, "expected exit code to match")
+			}
+
+			if tt.maxDuration > 0 {
+				assert.Less(t, time.Since(start), tt.maxDuration, "command should be terminated in time")
+			}
+		})
+	}
+}
@@ -4,28 +4,29 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"os/exec"
 )
 
-// execCmd executes a command along with its env variables
-func execCmd(tailArgs []string, chdirPath string, newEnv bool, envVars []string) (err error) {
+// newCmd creates a PowerShell command from the tail arguments which is killed when the context is done.
+func newCmd(ctx context.Context, tailArgs []string) (*exec.Cmd, error) {
 	ps, err := exec.LookPath("powershell.exe")
 	if err != nil {
-		return fmt.Errorf("error: executable 'powershell.exe' was not found.\n%v", err)
+		return nil, fmt.Errorf("error: executable 'powershell.exe' was not found.\n%v", err)
 	}
 	args := []string{"-NoProfile", "-NonInteractive", "-Command"}
 	args = append(args, "$ErrorActionPreference = \"Stop\"; ")
 	args = append(args, tailArgs...)
 	args = append(args, "; if ($LastExitCode -gt 0) { exit $LastExitCode };")
-	cmd := exec.Command(ps, args...)
-	cmd.Dir = chdirPath
-	if newEnv {
-		cmd.Env = envVars
-	}
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+	return exec.CommandContext(ctx, ps, args...), nil
+}
+
+// shellCmd creates a command which runs a command line through PowerShell.
+// The command is killed when the context is done.
+func shellCmd(ctx context.Context, cmdLine string) *exec.Cmd {
+	return exec.CommandContext(ctx, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", cmdLine)
 }
+
+// defaultShell is the shell used when no one is provided or found in the environment.
+const defaultShell = "powershell.exe"
@@ -4,6 +4,20 @@ import (
 	"github.com/joseluisq/cline/flag"
 )
 
+// defaultKeepVars contains the inherited variables preserved by the `--keep-defaults` flag.
+var defaultKeepVars = []string{
+	"PATH",
+	"HOME",
+	"USER",
+	"LOGNAME",
+	"SHELL",
+	"TERM",
+	"TZ",
+	"LANG",
+	"LC_*",
+	"TMPDIR",
+}
+
 var Flags = []flag.Flag{
 	flag.FlagString{
 		Name:    "file",
@@ -52,4 +66,90 @@ var Flags = []flag.Flag{
 		Value:   false,
 		Summary: "Read only environment variables from stdin and ignore the .env file",
 	},
+	flag.FlagStringSlice{
+		Name:    "keep",
+		Aliases: []string{"k"},
+		Summary: "Keep inherited variables matching comma-separated names or glob patterns when starting a new or empty environment",
+	},
+	flag.FlagBool{
+		Name:    "keep-defaults",
+		Value:   false,
+		Summary: "Keep common POSIX variables like PATH, HOME, TERM or TZ when starting a new or empty environment",
+	},
+	flag.FlagBool{
+		Name:    "expand-args",
+		Aliases: []string{"x"},
+		Value:   false,
+		Summary: "Expand ${VAR} references in command arguments using the final environment ($$ for a literal $)",
+	},
+	flag.FlagString{
+		Name:    "timeout",
+		Aliases: []string{"t"},
+		Summary: "Terminate the command with SIGTERM if it still runs after a duration like 30s or 5m (exit code 124)",
+	},
+	flag.FlagString{
+		Name:    "kill-after",
+		Summary: "Send SIGKILL if the command still runs after a grace period once the timeout expired",
+	},
+	flag.FlagInt{
+		Name:    "retry",
+		Aliases: []string{"r"},
+		Value:   0,
+		Summary: "Retry the command up to N times if it fails, exporting the attempt number as ENVE_ATTEMPT",
+	},
+	flag.FlagString{
+		Name:    "retry-delay",
+		Value:   "1s",
+		Summary: "Delay before retrying a failed command",
+	},
+	flag.FlagString{
+		Name:    "retry-backoff",
+		Value:   "constant",
+		Summary: "Backoff strategy applied to the retry delay using constant or exponential",
+	},
+	flag.FlagStringSlice{
+		Name:    "retry-on-exit",
+		Summary: "Retry only when the command exits with one of the comma-separated exit codes",
+	},
+	flag.FlagBool{
+		Name:    "watch",
+		Value:   false,
+		Summary: "Restart the command with a reloaded environment every time the env file changes",
+	},
+	flag.FlagStringSlice{
+		Name:    "watch-path",
+		Summary: "Additional comma-separated file or directory paths to watch for changes",
+	},
+	flag.FlagStringSlice{
+		Name:    "matrix",
+		Aliases: []string{"m"},
+		Summary: "Run the command once per comma-separated env file or glob pattern instead of the .env file",
+	},
+	flag.FlagInt{
+		Name:    "parallel",
+		Value:   1,
+		Summary: "Number of matrix runs executed at the same time",
+	},
+	flag.FlagBool{
+		Name:    "require-trust",
+		Value:   false,
+		Summary: "Refuse to load env files which were not trusted via 'enve allow' or were modified since then",
+	},
+	flag.FlagString{
+		Name:    "schema",
+		Summary: "Validate the final environment against a JSON or dotenv-style schema file, applying its defaults to unset variables",
+	},
+	flag.FlagString{
+		Name:    "key-file",
+		Summary: "Read the passphrase of encrypted env files from a file instead of the ENVE_KEY variable or a prompt",
+	},
+	flag.FlagString{
+		Name:    "verify-with",
+		Summary: "Refuse to load env files whose .sig signature does not match using the given Ed25519 public key file",
+	},
+	flag.FlagBool{
+		Name:    "allow-exec-refs",
+		Value:   false,
+		Summary: "Resolve ref+exec:// secret references of env files by running their shell commands",
+	},
 }
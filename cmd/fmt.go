@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/fs"
+)
+
+// commandFiles returns the env files given as tail arguments or the one of the `--file` flag.
+func commandFiles(ctx *app.CmdContext) ([]string, error) {
+	if len(ctx.TailArgs) > 0 {
+		return ctx.TailArgs, nil
+	}
+	// file option
+	fileF, err := ctx.AppContext.Flags().String("file")
+	if err != nil {
+		return nil, err
+	}
+	return []string{fileF.Value()}, nil
+}
+
+// fmtHandler formats the given env files (or the one of the `--file` flag).
+func fmtHandler(ctx *app.CmdContext) error {
+	// write option
+	writeF, err := ctx.Flags.Bool("write")
+	if err != nil {
+		return err
+	}
+	write, err := writeF.Value()
+	if err != nil {
+		return err
+	}
+
+	// check option
+	checkF, err := ctx.Flags.Bool("check")
+	if err != nil {
+		return err
+	}
+	check, err := checkF.Value()
+	if err != nil {
+		return err
+	}
+
+	// sort option
+	sortF, err := ctx.Flags.Bool("sort")
+	if err != nil {
+		return err
+	}
+	sortKeys, err := sortF.Value()
+	if err != nil {
+		return err
+	}
+
+	if write && check {
+		return fmt.Errorf("error: flag '--write' cannot be used along with '--check'")
+	}
+
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	unformatted := 0
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", file, err)
+		}
+		if isEncryptedFile(buf) {
+			return fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", file)
+		}
+		formatted, err := dotenv.Format(dotenv.ParseBytes(buf), dotenv.FormatOptions{Sort: sortKeys})
+		if err != nil {
+			return fmt.Errorf("error: cannot format file '%s'.\n%v", file, err)
+		}
+		changed := !bytes.Equal(buf, formatted)
+
+		switch {
+		case check:
+			if changed {
+				fmt.Println(file)
+				unformatted++
+			}
+		case write:
+			if changed {
+				if err := fs.WriteFileAtomic(file, formatted, 0644); err != nil {
+					return err
+				}
+			}
+		default:
+			fmt.Print(string(formatted))
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("error: %d of %d files are not formatted", unformatted, len(files))
+	}
+	return nil
+}
@@ -1,169 +1,170 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joseluisq/cline/app"
-
-	"github.com/joseluisq/enve/env"
-	"github.com/joseluisq/enve/fs"
 )
 
 func appHandler(ctx *app.AppContext) error {
 	var flags = ctx.Flags()
 
-	// ignore-environment option
-	ignoreEnvF, err := flags.Bool("ignore-environment")
+	environ, err := newEnviron(flags)
 	if err != nil {
 		return err
 	}
-	ignoreEnv, err := ignoreEnvF.Value()
+
+	// expand-args option
+	expandArgsF, err := flags.Bool("expand-args")
 	if err != nil {
 		return err
 	}
-
-	// no-file option
-	noFileF, err := flags.Bool("no-file")
+	expandArgs, err := expandArgsF.Value()
 	if err != nil {
 		return err
 	}
-	noFile, err := noFileF.Value()
+
+	// timeout option
+	var timeout time.Duration
+	timeoutF, err := flags.String("timeout")
 	if err != nil {
 		return err
 	}
+	if timeoutF.IsProvided() {
+		if timeout, err = parseDuration("timeout", timeoutF.Value()); err != nil {
+			return err
+		}
+	}
 
-	// file option
-	file, err := flags.String("file")
+	// kill-after option
+	var killAfter time.Duration
+	killAfterF, err := flags.String("kill-after")
 	if err != nil {
 		return err
 	}
-	filePath := file.Value()
+	if killAfterF.IsProvided() {
+		if !timeoutF.IsProvided() {
+			return fmt.Errorf("error: flag '--kill-after' requires '--timeout' to be provided")
+		}
+		if killAfter, err = parseDuration("kill-after", killAfterF.Value()); err != nil {
+			return err
+		}
+	}
 
-	// new-environment option
-	newEnvF, err := flags.Bool("new-environment")
+	// retry option
+	retryF, err := flags.Int("retry")
 	if err != nil {
 		return err
 	}
-	newEnv, err := newEnvF.Value()
+	retries, err := retryF.Value()
 	if err != nil {
 		return err
 	}
+	if retries < 0 {
+		return fmt.Errorf("error: invalid number of retries '%d' for flag '--retry'", retries)
+	}
 
-	var envVars env.Slice
-
-	// stdin option
-	stdinF, err := flags.Bool("stdin")
+	// retry-delay option
+	retryDelayF, err := flags.String("retry-delay")
 	if err != nil {
 		return err
 	}
-	stdin, err := stdinF.Value()
+	retryDelay, err := parseDuration("retry-delay", retryDelayF.Value())
 	if err != nil {
 		return err
 	}
 
-	// overwrite option
-	overwriteF, err := flags.Bool("overwrite")
+	// retry-backoff option
+	retryBackoffF, err := flags.String("retry-backoff")
 	if err != nil {
 		return err
 	}
-	overwrite, err := overwriteF.Value()
+	retryBackoff := retryBackoffF.Value()
+	if retryBackoff != backoffConstant && retryBackoff != backoffExponential {
+		return fmt.Errorf("error: retry backoff '%s' is not supported", retryBackoff)
+	}
+
+	// retry-on-exit option
+	retryOnExitF, err := flags.StringSlice("retry-on-exit")
 	if err != nil {
 		return err
 	}
-
-	output, err := flags.String("output")
+	retryExitCodes, err := parseExitCodes(retryOnExitF.Value())
 	if err != nil {
 		return err
 	}
 
-	// chdir option
-	chdirPath := ""
-	chdir, err := flags.String("chdir")
+	// watch option
+	watchF, err := flags.Bool("watch")
 	if err != nil {
 		return err
 	}
-	if chdir.IsProvided() {
-		chdirPath = chdir.Value()
-		if err := fs.DirExists(chdirPath); err != nil {
-			return err
-		}
-		if err := os.Chdir(chdirPath); err != nil {
-			return fmt.Errorf("error: cannot change directory to '%s'.\n%v", chdirPath, err)
-		}
+	watch, err := watchF.Value()
+	if err != nil {
+		return err
 	}
 
-	if stdin {
-		fi, err := os.Stdin.Stat()
-		if err != nil {
-			return fmt.Errorf("error: cannot read from stdin.\n%v", err)
+	// watch-path option
+	watchPathF, err := flags.StringSlice("watch-path")
+	if err != nil {
+		return err
+	}
+	var watchPaths []string
+	for _, p := range watchPathF.Value() {
+		if p != "" {
+			watchPaths = append(watchPaths, p)
 		}
-		if (fi.Mode() & os.ModeCharDevice) == 0 {
-			envr := env.FromReader(os.Stdin)
+	}
 
-			if ignoreEnv {
-				goto ContinueEnvProc
-			}
+	// matrix option
+	matrixF, err := flags.StringSlice("matrix")
+	if err != nil {
+		return err
+	}
 
-			if newEnv {
-				vmap, err := envr.Parse()
-				if err != nil {
-					return err
-				}
-				envVars = vmap.Array()
-			} else {
-				if err := envr.Load(overwrite); err != nil {
-					str := ""
-					if overwrite {
-						str = " (overwrite)"
-					}
-					return fmt.Errorf("error: cannot load env from stdin%s.\n%v", str, err)
-				}
-				envVars = env.Slice(os.Environ())
-			}
+	// parallel option
+	parallelF, err := flags.Int("parallel")
+	if err != nil {
+		return err
+	}
+	parallel, err := parallelF.Value()
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		return fmt.Errorf("error: invalid number of parallel runs '%d' for flag '--parallel'", parallel)
+	}
 
-			goto ContinueEnvProc
-		}
+	output, err := flags.String("output")
+	if err != nil {
+		return err
 	}
 
-	if !ignoreEnv {
-		if noFile {
-			if newEnv || ignoreEnv {
-				goto ContinueEnvProc
-			}
+	tailArgs := ctx.TailArgs()
 
-			envVars = env.Slice(os.Environ())
-			goto ContinueEnvProc
+	if matrixF.IsProvided() {
+		if len(tailArgs) == 0 {
+			return fmt.Errorf("error: flag '--matrix' requires a command to execute")
 		}
-
-		// .env file processing
-		envf, err := env.FromPath(filePath)
+		if environ.stdin || environ.ignoreEnv || output.IsProvided() || watch || retryF.IsProvided() {
+			return fmt.Errorf("error: flag '--matrix' cannot be used along with '--stdin', '--ignore-environment', '--output', '--watch' or '--retry'")
+		}
+		files, err := expandMatrix(matrixF.Value())
 		if err != nil {
 			return err
 		}
-		defer envf.Close()
-
-		if newEnv {
-			vmap, err := envf.Parse()
-			if err != nil {
-				return err
-			}
-			envVars = vmap.Array()
-		} else {
-			if err := envf.Load(overwrite); err != nil {
-				str := ""
-				if overwrite {
-					str = " (overwrite)"
-				}
-				return fmt.Errorf("error: cannot load env from file%s.\n%v", str, err)
-			}
-
-			envVars = env.Slice(os.Environ())
-		}
+		return execCmdMatrix(environ, files, tailArgs, expandArgs, parallel, timeout, killAfter)
 	}
 
-ContinueEnvProc:
-	tailArgs := ctx.TailArgs()
+	if err := environ.load(); err != nil {
+		return err
+	}
+	envVars := environ.vars
 
 	totalFags := len(flags.GetProvided())
 	noFlags := totalFags == 0
@@ -180,32 +181,44 @@ ContinueEnvProc:
 			return fmt.Errorf("error: output format cannot be used when executing a command")
 		}
 
-		return execCmd(tailArgs, chdirPath, newEnv, envVars)
-	}
+		if watch {
+			files := environ.files()
+			if len(files) == 0 {
+				return fmt.Errorf("error: flag '--watch' requires an env file to be loaded")
+			}
+			if timeoutF.IsProvided() || retryF.IsProvided() {
+				return fmt.Errorf("error: flag '--watch' cannot be used along with '--timeout' or '--retry'")
+			}
 
-OutputEnvProc:
-	out := output.Value()
-	switch out {
-	case "text":
-		fmt.Println(envVars.Text())
-	case "json":
-		if buf, err := envVars.JSON(); err != nil {
-			return err
-		} else {
-			fmt.Println(string(buf))
+			w := &watcher{
+				paths:      append(files, watchPaths...),
+				interval:   watchInterval,
+				debounce:   watchDebounce,
+				killAfter:  watchKillAfter,
+				expandArgs: expandArgs,
+			}
+			sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return w.run(sigCtx, tailArgs, environ.chdirPath, environ.reload)
 		}
-	case "xml":
-		if buf, err := envVars.XML(); err != nil {
-			return err
-		} else {
-			fmt.Println("<?xml version=\"1.0\" encoding=\"UTF-8\"?>" + string(buf))
+
+		if expandArgs {
+			tailArgs = envVars.Expand(tailArgs)
 		}
-	default:
-		if out == "" {
-			return fmt.Errorf("error: output format was empty or not provided")
+
+		if retryF.IsProvided() {
+			opts := retryOpts{
+				retries:   retries,
+				delay:     retryDelay,
+				backoff:   retryBackoff,
+				exitCodes: retryExitCodes,
+			}
+			return execCmdRetry(tailArgs, environ.chdirPath, environ.isolated(), envVars, timeout, killAfter, opts)
 		}
-		return fmt.Errorf("error: output format '%s' is not supported", out)
+
+		return execCmdTimeout(tailArgs, environ.chdirPath, environ.isolated(), envVars, timeout, killAfter)
 	}
 
-	return nil
+OutputEnvProc:
+	return printOutput(output.Value(), envVars.Text(), envVars.Environ())
 }
@@ -24,6 +24,12 @@ import (
 const validEnvFile = "valid.env"
 const invalidEnvFile = "invalid.env"
 
+// Recipients of the identities found in the crypt fixtures.
+const (
+	identityRecipient = "age1qt8g8me2w4vqp75c6qsytz3j9jp7xvds583fztffl5dvnax6eydqaecl7m"
+	otherRecipient    = "age1c3ryg859gyyw2cvxr3lw99u3mfpft0ju5gtr6ta2ce7hyd730gzqzrcr3q"
+)
+
 func TestAppHandler_Output(t *testing.T) {
 	CWD, err := os.Getwd()
 	if err != nil {
@@ -32,6 +38,41 @@ func TestAppHandler_Output(t *testing.T) {
 
 	var baseDirPath = filepath.Join(CWD, "../")
 	var fixturePath = filepath.Join(baseDirPath, "fixtures", "handler")
+	var dataDirPath = t.TempDir()
+	var writeFilePath = filepath.Join(t.TempDir(), ".env")
+	var encryptFilePath = filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(encryptFilePath, []byte("SECRET=\"s3cr3t value\"\n"), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	if err := os.WriteFile(writeFilePath, []byte("B = 2\nA = 1\n"), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	var signFilePath = filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(signFilePath, []byte("HOST=localhost\n"), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	var secretDirPath = t.TempDir()
+	var secretRefsFilePath = filepath.Join(secretDirPath, ".env")
+	if err := os.WriteFile(filepath.Join(secretDirPath, "db"), []byte("s3cr3t\n"), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	if err := os.WriteFile(secretRefsFilePath, []byte(
+		"DB_PASSWORD=ref+file://"+filepath.Join(secretDirPath, "db")+"\n"+
+			"TOKEN=ref+exec://echo t0k3n\n"+
+			"OTHER=ref+env://ENVE_TEST_OTHER\n",
+	), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	var brokenRefsFilePath = filepath.Join(secretDirPath, "broken.env")
+	if err := os.WriteFile(brokenRefsFilePath, []byte("HOST=localhost\nOTHER=ref+env://ENVE_TEST_MISSING\n"), 0600); err != nil {
+		assert.Fail(t, "Failed to create file for tests", err)
+	}
+	var rekeyDirPath = t.TempDir()
+	for _, name := range []string{"a.env", "b.env", "plain.env"} {
+		if err := os.WriteFile(filepath.Join(rekeyDirPath, name), []byte("SECRET=s3cr3t\n"), 0600); err != nil {
+			assert.Fail(t, "Failed to create file for tests", err)
+		}
+	}
 
 	var newArgs = func(args []string) []string {
 		return append([]string{"enve-test"}, args...)
@@ -64,6 +105,7 @@ func TestAppHandler_Output(t *testing.T) {
 		expectedJSON *env.Environment
 		expectedXML  *env.Environment
 		expectedErr  error
+		expectedCode int
 	}{
 		{
 			name:         "should output nothing with no args provided",
@@ -85,8 +127,44 @@ func TestAppHandler_Output(t *testing.T) {
 				"-i --ignore-environment",
 				"-z --no-file",
 				"-s --stdin",
+				"-k --keep",
+				"--keep-defaults",
+				"-x --expand-args",
+				"-t --timeout",
+				"--kill-after",
+				"-r --retry",
+				"--retry-delay",
+				"--retry-backoff",
+				"--retry-on-exit",
+				"--watch",
+				"--watch-path",
+				"-m --matrix",
+				"--parallel",
+				"--require-trust",
+				"--schema",
 				"-h --help",
 				"-v --version",
+				"COMMANDS:",
+				"start",
+				"shell",
+				"hook",
+				"export",
+				"allow",
+				"deny",
+				"check",
+				"lint",
+				"fmt",
+				"set",
+				"get",
+				"unset",
+				"diff",
+				"merge",
+				"convert",
+				"encrypt",
+				"decrypt",
+				"recipients",
+				"rekey",
+				"sign",
 			},
 		},
 		{
@@ -375,6 +453,909 @@ func TestAppHandler_Output(t *testing.T) {
 			args:        newArgsDefaultInvalid([]string{"--new-environment", "-o", "json"}),
 			expectedErr: errors.New("unexpected character \"{\" in variable name near \""),
 		},
+		{
+			name: "should keep inherited variables matching patterns with --new-environment",
+			args: newArgsDefault([]string{"--new-environment", "--keep", "KEEP_*,OTHER", "-o", "json"}),
+			initialEnvs: []string{
+				"KEEP_ME=yes",
+				"KEEP_TOO=yes",
+			},
+			expectedJSON: &env.Environment{
+				Env: []env.EnvironmentVar{
+					{Name: "KEEP_ME", Value: "yes"},
+					{Name: "KEEP_TOO", Value: "yes"},
+					{Name: "HOST", Value: "127.0.0.1"},
+					{Name: "PORT", Value: "8080"},
+				},
+			},
+		},
+		{
+			name: "should prefer the env file variables over the kept ones",
+			args: newArgsDefault([]string{"--new-environment", "--keep", "HOST,KEEP_ME", "-o", "json"}),
+			initialEnvs: []string{
+				"HOST=0.0.0.0",
+				"KEEP_ME=yes",
+			},
+			expectedJSON: &env.Environment{
+				Env: []env.EnvironmentVar{
+					{Name: "HOST", Value: "127.0.0.1"},
+					{Name: "PORT", Value: "8080"},
+					{Name: "KEEP_ME", Value: "yes"},
+				},
+			},
+		},
+		{
+			name: "should keep default variables with --ignore-environment",
+			args: newArgsDefault([]string{"--ignore-environment", "--keep-defaults", "-o", "xml"}),
+			initialEnvs: []string{
+				"TZ=UTC",
+				"LC_TIME=C",
+			},
+			expectedXML: &env.Environment{
+				Env: []env.EnvironmentVar{
+					{Name: "TZ", Value: "UTC"},
+					{Name: "LC_TIME", Value: "C"},
+				},
+			},
+		},
+		{
+			name:        "should return an error for an invalid keep pattern",
+			args:        newArgsDefault([]string{"--new-environment", "--keep", "[HOST", "-o", "json"}),
+			expectedErr: errors.New("error: invalid keep pattern."),
+		},
+		{
+			name:         "should expand variables in command arguments",
+			args:         newArgsDefault([]string{"--overwrite", "--expand-args", "echo", "${HOST}:${PORT}", "$${HOST}", "$PORT"}),
+			expectedText: []string{"127.0.0.1:8080 ${HOST} $PORT"},
+		},
+		{
+			name:         "should expand variables in command arguments with --new-environment",
+			args:         newArgsDefault([]string{"-n", "-x", "echo", "${LOG_LEVEL}", "${UNDEFINED_VAR}-"}),
+			expectedText: []string{"info -"},
+		},
+		{
+			name:         "should not expand variables in command arguments by default",
+			args:         newArgsDefault([]string{"echo", "${HOST}"}),
+			expectedText: []string{"${HOST}"},
+		},
+		{
+			name:        "should return an error when the command times out",
+			args:        newArgsDefault([]string{"--timeout", "100ms", "sleep", "2"}),
+			expectedErr: errors.New("error: command timed out after 100ms"),
+		},
+		{
+			name:         "should execute the command when it finishes before the timeout",
+			args:         newArgsDefault([]string{"--timeout", "5", "--kill-after", "1s", "echo", "in time"}),
+			expectedText: []string{"in time"},
+		},
+		{
+			name:        "should return an error for an invalid timeout",
+			args:        newArgsDefault([]string{"--timeout", "soon", "echo", "hello"}),
+			expectedErr: errors.New("error: invalid duration 'soon' for flag '--timeout'"),
+		},
+		{
+			name:        "should return an error when using kill-after without timeout",
+			args:        newArgsDefault([]string{"--kill-after", "1s", "echo", "hello"}),
+			expectedErr: errors.New("error: flag '--kill-after' requires '--timeout' to be provided"),
+		},
+		{
+			name:         "should execute the command with retries",
+			args:         newArgsDefault([]string{"--retry", "2", "--retry-delay", "10ms", "echo", "retried"}),
+			expectedText: []string{"retried"},
+		},
+		{
+			name:        "should return an error for an unsupported retry backoff",
+			args:        newArgsDefault([]string{"--retry", "1", "--retry-backoff", "linear", "echo", "hello"}),
+			expectedErr: errors.New("error: retry backoff 'linear' is not supported"),
+		},
+		{
+			name:        "should return an error for an invalid retry delay",
+			args:        newArgsDefault([]string{"--retry", "1", "--retry-delay", "later", "echo", "hello"}),
+			expectedErr: errors.New("error: invalid duration 'later' for flag '--retry-delay'"),
+		},
+		{
+			name:        "should return an error for an invalid retry exit code",
+			args:        newArgsDefault([]string{"--retry", "1", "--retry-on-exit", "1,x", "echo", "hello"}),
+			expectedErr: errors.New("error: invalid exit code 'x' for flag '--retry-on-exit'"),
+		},
+		{
+			name:        "should return an error when watching without an env file",
+			args:        newArgsDefault([]string{"--watch", "--no-file", "echo", "hello"}),
+			expectedErr: errors.New("error: flag '--watch' requires an env file to be loaded"),
+		},
+		{
+			name:        "should return an error when watching along with a timeout",
+			args:        newArgsDefault([]string{"--watch", "--timeout", "1s", "echo", "hello"}),
+			expectedErr: errors.New("error: flag '--watch' cannot be used along with '--timeout' or '--retry'"),
+		},
+		{
+			name:         "should start the processes of a Procfile",
+			args:         newArgsDefault([]string{"start", "--procfile", filepath.Join(fixturePath, "Procfile"), "--port", "3000"}),
+			expectedText: []string{"starting web on port 3000", "web  | started"},
+		},
+		{
+			name:        "should return an error when the Procfile does not exist",
+			args:        newArgsDefault([]string{"start", "--procfile", filepath.Join(fixturePath, "Procfile-xyz")}),
+			expectedErr: fmt.Errorf("error: cannot access file '%s'.", filepath.Join(fixturePath, "Procfile-xyz")),
+		},
+		{
+			name:        "should return an error when a Procfile process does not exist",
+			args:        newArgsDefault([]string{"start", "-p", filepath.Join(fixturePath, "Procfile"), "worker"}),
+			expectedErr: fmt.Errorf("error: process 'worker' was not found in '%s'", filepath.Join(fixturePath, "Procfile")),
+		},
+		{
+			name:        "should return an error when the shell does not exist",
+			args:        newArgsDefault([]string{"shell", "--shell", "enve-shell-xyz"}),
+			expectedErr: errors.New("error: shell 'enve-shell-xyz' was not found."),
+		},
+		{
+			name:         "should print the shell hook script",
+			args:         newArgsDefault([]string{"hook", "zsh"}),
+			expectedText: []string{"_enve_hook() {", "export zsh)", "chpwd_functions"},
+		},
+		{
+			name:         "should print the shell hook script verifying signatures",
+			args:         newArgs([]string{"--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"), "hook", "bash"}),
+			expectedText: []string{"export bash)", "--verify-with " + filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub")},
+		},
+		{
+			name:        "should return an error for an unsupported hook shell",
+			args:        newArgsDefault([]string{"hook", "tcsh"}),
+			expectedErr: errors.New("error: shell 'tcsh' is not supported, use one of bash, zsh or fish"),
+		},
+		{
+			name:        "should return an error when requiring trust of an untrusted env file",
+			args:        newArgsDefault([]string{"--require-trust", "--output", "text"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath},
+			expectedErr: fmt.Errorf("error: env file '%s' is not trusted, run 'enve allow", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name:         "should not export an untrusted env file",
+			args:         newArgsDefault([]string{"export", "bash"}),
+			initialEnvs:  []string{"XDG_DATA_HOME=" + dataDirPath, "ENVE_HOOK="},
+			expectedText: []string{},
+		},
+		{
+			name:        "should return an error when requiring trust along with stdin",
+			args:        newArgs([]string{"--stdin", "--require-trust", "--output", "text"}),
+			expectedErr: errors.New("error: flag '--require-trust' cannot be used along with '--stdin'"),
+		},
+		{
+			name:        "should allow an env file",
+			args:        newArgsDefault([]string{"allow"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath},
+		},
+		{
+			name:         "should export a trusted env file",
+			args:         newArgsDefault([]string{"export", "bash"}),
+			initialEnvs:  []string{"XDG_DATA_HOME=" + dataDirPath, "ENVE_HOOK="},
+			expectedText: []string{"export ENVE_HOOK="},
+		},
+		{
+			name:        "should return an error when exporting an unsigned env file verifying its signature",
+			args:        newArgsDefault([]string{"--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"), "export", "bash"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath, "ENVE_HOOK="},
+			expectedErr: fmt.Errorf("error: env file '%[1]s' is not signed, signature file '%[1]s.sig' was not found", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name:        "should load a trusted env file when requiring trust",
+			args:        newArgsDefault([]string{"--require-trust", "--output", "text"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath},
+			expectedText: []string{
+				"HOST=127.0.0.1",
+			},
+		},
+		{
+			name:        "should deny an env file",
+			args:        newArgsDefault([]string{"deny"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath},
+		},
+		{
+			name:        "should return an error when requiring trust of a denied env file",
+			args:        newArgsDefault([]string{"--require-trust", "echo", "hello"}),
+			initialEnvs: []string{"XDG_DATA_HOME=" + dataDirPath},
+			expectedErr: fmt.Errorf("error: env file '%s' is not trusted", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name: "should apply the schema defaults to unset variables",
+			args: newArgsDefault([]string{"-n", "--schema", filepath.Join(fixturePath, "valid.schema.json"), "--output", "text"}),
+			expectedText: []string{
+				"HOST=127.0.0.1",
+				"TIMEOUT=30s",
+				"WORKERS=4",
+			},
+		},
+		{
+			name: "should return all the schema violations",
+			args: newArgsDefault([]string{"-n", "--schema", filepath.Join(fixturePath, "invalid.schema.json"), "echo", "hello"}),
+			expectedErr: fmt.Errorf(
+				"error: environment does not match schema '%s' (4 violations):\n"+
+					"  API_URL: required variable is not set\n"+
+					"  DEBUG: value 'true' is not a valid integer\n"+
+					"  LOG_LEVEL: value 'info' is not one of warn, error\n"+
+					"  PORT: value '8080' is not one of 80, 443",
+				filepath.Join(fixturePath, "invalid.schema.json"),
+			),
+		},
+		{
+			name:        "should return an error when the schema file does not exist",
+			args:        newArgsDefault([]string{"--schema", filepath.Join(fixturePath, "missing.schema.json"), "echo", "hello"}),
+			expectedErr: fmt.Errorf("error: cannot access file '%s'.", filepath.Join(fixturePath, "missing.schema.json")),
+		},
+		{
+			name:         "should check the env file against an example file",
+			args:         newArgsDefault([]string{"check", "--example", filepath.Join(fixturePath, "valid.env.example")}),
+			expectedText: []string{"0 missing, 0 extra and 0 empty variables"},
+		},
+		{
+			name: "should output the check report as json",
+			args: newArgsDefault([]string{"check", "-e", filepath.Join(fixturePath, "valid.env.example"), "-o", "json"}),
+			expectedText: []string{
+				`"missing":0,"extra":0,"empty":0,"issues":[]}`,
+			},
+		},
+		{
+			name:        "should return an error when the env file does not match the example file",
+			args:        newArgsDefault([]string{"check", "--example", filepath.Join(fixturePath, "missing.env.example")}),
+			expectedErr: fmt.Errorf("error: environment does not match example file '%s'", filepath.Join(fixturePath, "missing.env.example")),
+		},
+		{
+			name:        "should return an error when the example file does not exist",
+			args:        newArgsDefault([]string{"check", "--example", filepath.Join(fixturePath, "xyz.env.example")}),
+			expectedErr: fmt.Errorf("error: cannot access file '%s'.", filepath.Join(fixturePath, "xyz.env.example")),
+		},
+		{
+			name:         "should lint a valid env file",
+			args:         newArgs([]string{"lint", filepath.Join(baseDirPath, "fixtures", "lint", "valid.env")}),
+			expectedText: []string{""},
+		},
+		{
+			name: "should return an error when linting an invalid env file",
+			args: newArgs([]string{"lint", filepath.Join(baseDirPath, "fixtures", "lint", "invalid.env")}),
+			expectedText: []string{
+				filepath.Join(baseDirPath, "fixtures", "lint", "invalid.env") + ":3: error: duplicate key 'HOST' previously declared at line 2 (duplicate-key)",
+				filepath.Join(baseDirPath, "fixtures", "lint", "invalid.env") + ":4: warning: unquoted value of 'name' contains whitespace (unquoted-space)",
+			},
+			expectedErr: errors.New("error: found 3 problems"),
+		},
+		{
+			name:         "should output the lint findings as sarif",
+			args:         newArgs([]string{"lint", "--disable", "key-case,unquoted-space", "-o", "sarif", filepath.Join(baseDirPath, "fixtures", "lint", "invalid.env")}),
+			expectedText: []string{`"version": "2.1.0"`, `"ruleId": "duplicate-key"`, `"startLine": 3`},
+			expectedErr:  errors.New("error: found 1 problems"),
+		},
+		{
+			name:        "should return an error for an unsupported lint rule",
+			args:        newArgs([]string{"lint", "--disable", "xyz", filepath.Join(baseDirPath, "fixtures", "lint", "valid.env")}),
+			expectedErr: errors.New("error: lint rule 'xyz' is not supported"),
+		},
+		{
+			name:         "should print the formatted env file",
+			args:         newArgs([]string{"fmt", filepath.Join(baseDirPath, "fixtures", "fmt", "unformatted.env")}),
+			expectedText: []string{"# Formatter testing configuration\nHOST=127.0.0.1\nNAME=\"my app\"\n\nPORT=8080\n"},
+		},
+		{
+			name: "should return an error when checking unformatted env files",
+			args: newArgs([]string{
+				"fmt", "--check",
+				filepath.Join(baseDirPath, "fixtures", "fmt", "formatted.env"),
+				filepath.Join(baseDirPath, "fixtures", "fmt", "unformatted.env"),
+			}),
+			expectedText: []string{filepath.Join(baseDirPath, "fixtures", "fmt", "unformatted.env")},
+			expectedErr:  errors.New("error: 1 of 2 files are not formatted"),
+		},
+		{
+			name: "should check a formatted env file",
+			args: newArgs([]string{"fmt", "--check", filepath.Join(baseDirPath, "fixtures", "fmt", "formatted.env")}),
+		},
+		{
+			name: "should write the formatted and sorted env file",
+			args: newArgs([]string{"fmt", "-w", "--sort", writeFilePath}),
+		},
+		{
+			name:         "should print the written env file",
+			args:         newArgs([]string{"-f", writeFilePath, "fmt"}),
+			expectedText: []string{"A=1\nB=2\n"},
+		},
+		{
+			name: "should set variables in an env file",
+			args: newArgs([]string{"set", "-f", writeFilePath, "A=my app", "C=3"}),
+		},
+		{
+			name:         "should get a variable from an env file",
+			args:         newArgs([]string{"-f", writeFilePath, "get", "A"}),
+			expectedText: []string{"my app\n"},
+		},
+		{
+			name: "should unset variables in an env file",
+			args: newArgs([]string{"unset", "-f", writeFilePath, "B"}),
+		},
+		{
+			name:         "should print the edited env file",
+			args:         newArgs([]string{"-f", writeFilePath, "fmt"}),
+			expectedText: []string{"A=\"my app\"\nC=3\n"},
+		},
+		{
+			name:        "should return an error when getting a missing variable",
+			args:        newArgs([]string{"get", "-f", writeFilePath, "B"}),
+			expectedErr: fmt.Errorf("error: variable 'B' is not set in file '%s'", writeFilePath),
+		},
+		{
+			name:        "should return an error when setting a variable without value",
+			args:        newArgs([]string{"set", "-f", writeFilePath, "B"}),
+			expectedErr: errors.New("error: invalid variable 'B', use the KEY=VALUE format"),
+		},
+		{
+			name: "should print the differences between two env files",
+			args: newArgs([]string{
+				"diff",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedText: []string{
+				"-DEBUG=true\n-HOST=staging.example.com\n+HOST=example.com\n+WORKERS=4\n",
+			},
+			expectedErr: fmt.Errorf(
+				"error: found 3 differences between '%s' and '%s'",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			),
+			expectedCode: 1,
+		},
+		{
+			name: "should print the masked differences between two env files in json format",
+			args: newArgs([]string{
+				"diff", "--mask", "-o", "json",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedText: []string{
+				`"changes":[{"kind":"removed","name":"DEBUG","old_value":"****"},` +
+					`{"kind":"changed","name":"HOST","old_value":"****","new_value":"****"},` +
+					`{"kind":"added","name":"WORKERS","new_value":"****"}]}`,
+			},
+			expectedErr:  errors.New("exit status 1"),
+			expectedCode: 1,
+		},
+		{
+			name: "should print the differences between the environment and an env file as a patch",
+			args: newArgs([]string{
+				"diff", "--current", "-o", "patch",
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			initialEnvs:  []string{"HOST=example.com", "PORT=80", "NAME=my app", "WORKERS=4"},
+			expectedText: []string{"enve set PORT=8080\n"},
+			expectedErr:  errors.New("exit status 1"),
+			expectedCode: 1,
+		},
+		{
+			name: "should not print differences between equal env files",
+			args: newArgs([]string{
+				"diff",
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedText: []string{},
+		},
+		{
+			name:         "should return an error when diffing a single env file",
+			args:         newArgs([]string{"diff", filepath.Join(baseDirPath, "fixtures", "diff", "production.env")}),
+			expectedErr:  errors.New("error: two env files are required, use 'enve diff FILE FILE'"),
+			expectedCode: 2,
+		},
+		{
+			name: "should return an error when masking a patch",
+			args: newArgs([]string{
+				"diff", "--mask", "-o", "patch", "--current",
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedErr: errors.New("error: flag '--mask' cannot be used along with the patch output"),
+		},
+		{
+			name: "should print the merged env files",
+			args: newArgs([]string{
+				"merge",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedText: []string{
+				"# Staging configuration\nHOST=example.com\nPORT=8080\nDEBUG=true\nNAME=\"my app\"\nWORKERS=4\n",
+			},
+		},
+		{
+			name: "should write the merged env files",
+			args: newArgs([]string{
+				"merge", "-p", "left", "-o", writeFilePath,
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+		},
+		{
+			name:         "should get a variable from the merged env file",
+			args:         newArgs([]string{"-f", writeFilePath, "get", "HOST"}),
+			expectedText: []string{"staging.example.com\n"},
+		},
+		{
+			name: "should return an error when merging conflicting env files",
+			args: newArgs([]string{
+				"merge", "--prefer", "error-on-conflict",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedErr: fmt.Errorf(
+				"error: cannot merge file '%s' into '%s' because of conflicting values for 'HOST'",
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+			),
+		},
+		{
+			name: "should return an error when merging with an unsupported strategy",
+			args: newArgs([]string{
+				"merge", "--prefer", "both",
+				filepath.Join(baseDirPath, "fixtures", "diff", "staging.env"),
+				filepath.Join(baseDirPath, "fixtures", "diff", "production.env"),
+			}),
+			expectedErr: errors.New("error: merge strategy 'both' is not supported, use left, right or error-on-conflict"),
+		},
+		{
+			name: "should convert a json file to dotenv format",
+			args: newArgs([]string{
+				"convert", "--from", "json", "--to", "dotenv",
+				filepath.Join(baseDirPath, "fixtures", "convert", "config.json"),
+			}),
+			expectedText: []string{"HOST=127.0.0.1\nPORT=8080\nDEBUG=true\nNAME=\"my app\"\n"},
+		},
+		{
+			name: "should convert an env file to json format",
+			args: newArgs([]string{
+				"convert", "--from", "dotenv", "--to", "json",
+				filepath.Join(fixturePath, validEnvFile),
+			}),
+			expectedText: []string{
+				`{"environment":[{"name":"HOST","value":"127.0.0.1"},{"name":"PORT","value":"8080"},` +
+					`{"name":"DEBUG","value":"true"},{"name":"LOG_LEVEL","value":"info"}]}`,
+			},
+		},
+		{
+			name: "should convert the stdin from xml to dotenv format",
+			args: newArgs([]string{"convert", "--from", "xml", "--to", "dotenv"}),
+			expectedStdin: []byte(
+				`<?xml version="1.0" encoding="UTF-8"?><Environment><Env><Name>A</Name><Value>1 2</Value></Env></Environment>`,
+			),
+			expectedText: []string{"A=\"1 2\"\n"},
+		},
+		{
+			name:        "should return an error when the output format is not provided",
+			args:        newArgs([]string{"convert", "--from", "json", filepath.Join(baseDirPath, "fixtures", "convert", "config.json")}),
+			expectedErr: errors.New("error: output format was not provided, use the '--to' flag"),
+		},
+		{
+			name:        "should return an error when the input format is not supported",
+			args:        newArgs([]string{"convert", "--from", "yaml", "--to", "json"}),
+			expectedErr: errors.New("error: input format 'yaml' is not supported, use dotenv, json or xml"),
+		},
+		{
+			name: "should return an error when the input cannot be parsed",
+			args: newArgs([]string{
+				"convert", "--from", "json", "--to", "dotenv",
+				filepath.Join(fixturePath, validEnvFile),
+			}),
+			expectedErr: fmt.Errorf("error: cannot parse json input from %s.", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name:         "should load an encrypted env file using the key variable",
+			args:         newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env")}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{"HOST=127.0.0.1\n", "PORT=8080\n", "DEBUG=true\n", "LOG_LEVEL=info\n"},
+		},
+		{
+			name: "should load an encrypted env file using a key file",
+			args: newArgs([]string{
+				"-n", "--key-file", filepath.Join(baseDirPath, "fixtures", "crypt", "enve.key"),
+				"-f", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env"),
+			}),
+			initialEnvs:  []string{"ENVE_KEY=wrong"},
+			expectedText: []string{"LOG_LEVEL=info"},
+		},
+		{
+			name:        "should return an error when loading an encrypted env file using a wrong key",
+			args:        newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env")}),
+			initialEnvs: []string{"ENVE_KEY=wrong"},
+			expectedErr: fmt.Errorf("error: cannot decrypt file '%s'.\nthe passphrase is wrong or the file was modified", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env")),
+		},
+		{
+			name:        "should return an error when loading an encrypted env file without key",
+			args:        newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env")}),
+			initialEnvs: []string{"ENVE_KEY="},
+			expectedErr: fmt.Errorf("error: no passphrase for file '%s', provide it via ENVE_KEY or --key-file", filepath.Join(baseDirPath, "fixtures", "crypt", "encrypted.env")),
+		},
+		{
+			name:        "should encrypt an env file",
+			args:        newArgs([]string{"encrypt", encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:        "should return an error when encrypting an encrypted env file",
+			args:        newArgs([]string{"encrypt", encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+			expectedErr: fmt.Errorf("error: file '%s' is already encrypted", encryptFilePath),
+		},
+		{
+			name:        "should return an error when editing an encrypted env file",
+			args:        newArgs([]string{"set", "-f", encryptFilePath, "A=1"}),
+			expectedErr: fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", encryptFilePath),
+		},
+		{
+			name:         "should get a variable from an encrypted env file",
+			args:         newArgs([]string{"-f", encryptFilePath, "get", "SECRET"}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{"s3cr3t value\n"},
+		},
+		{
+			name:         "should print a decrypted env file",
+			args:         newArgs([]string{"decrypt", "--stdout", encryptFilePath}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{"SECRET=\"s3cr3t value\"\n"},
+		},
+		{
+			name:        "should decrypt an env file",
+			args:        newArgs([]string{"decrypt", encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:        "should return an error when decrypting a plain env file",
+			args:        newArgs([]string{"decrypt", encryptFilePath}),
+			expectedErr: fmt.Errorf("error: file '%s' is not encrypted", encryptFilePath),
+		},
+		{
+			name:        "should encrypt the values of an env file",
+			args:        newArgs([]string{"encrypt", "--values-only", encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:         "should lint an env file with encrypted values",
+			args:         newArgs([]string{"lint", encryptFilePath}),
+			expectedText: []string{},
+		},
+		{
+			name:         "should get a variable from an env file with encrypted values",
+			args:         newArgs([]string{"-f", encryptFilePath, "get", "SECRET"}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{"s3cr3t value\n"},
+		},
+		{
+			name:        "should return an error when loading an env file with encrypted values using a wrong key",
+			args:        newArgs([]string{"-f", encryptFilePath, "get", "SECRET"}),
+			initialEnvs: []string{"ENVE_KEY=wrong"},
+			expectedErr: fmt.Errorf("error: cannot decrypt the values of file '%s'.\nthe passphrase is wrong or the file was modified", encryptFilePath),
+		},
+		{
+			name:        "should return an error when formatting an env file with encrypted values",
+			args:        newArgs([]string{"fmt", encryptFilePath}),
+			expectedErr: fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", encryptFilePath),
+		},
+		{
+			name:        "should return an error when merging an env file with encrypted values",
+			args:        newArgs([]string{"merge", filepath.Join(fixturePath, validEnvFile), encryptFilePath}),
+			expectedErr: fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", encryptFilePath),
+		},
+		{
+			name:         "should print an env file with decrypted values",
+			args:         newArgs([]string{"decrypt", "--stdout", encryptFilePath}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{"SECRET=\"s3cr3t value\"\n"},
+		},
+		{
+			name:        "should decrypt the values of an env file",
+			args:        newArgs([]string{"decrypt", encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:         "should load an env file encrypted for recipients using the identity variable",
+			args:         newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "crypt", "recipients.env")}),
+			initialEnvs:  []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+			expectedText: []string{"DB_PASSWORD=s3cr3t"},
+		},
+		{
+			name:        "should return an error when loading an env file encrypted for recipients without identity",
+			args:        newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "crypt", "recipients.env")}),
+			initialEnvs: []string{"ENVE_IDENTITY="},
+			expectedErr: fmt.Errorf("error: file '%s' is encrypted for recipients, provide an identity file via ENVE_IDENTITY", filepath.Join(baseDirPath, "fixtures", "crypt", "recipients.env")),
+		},
+		{
+			name:        "should return an error when encrypting for an invalid recipient",
+			args:        newArgs([]string{"encrypt", "--recipient", "age1invalid", encryptFilePath}),
+			expectedErr: fmt.Errorf("error: cannot encrypt file '%s'.\nrecipient 'age1invalid' is invalid", encryptFilePath),
+		},
+		{
+			name:        "should encrypt an env file for recipients",
+			args:        newArgs([]string{"encrypt", "--recipient", identityRecipient, encryptFilePath}),
+			initialEnvs: []string{"ENVE_KEY="},
+		},
+		{
+			name:         "should list the recipients of an env file",
+			args:         newArgs([]string{"recipients", "-f", encryptFilePath}),
+			initialEnvs:  []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+			expectedText: []string{identityRecipient + "\n"},
+		},
+		{
+			name:         "should get a variable from an env file encrypted for recipients",
+			args:         newArgs([]string{"-f", encryptFilePath, "get", "SECRET"}),
+			initialEnvs:  []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+			expectedText: []string{"s3cr3t value\n"},
+		},
+		{
+			name:        "should add a recipient to an env file",
+			args:        newArgs([]string{"recipients", "-f", encryptFilePath, "add", otherRecipient}),
+			initialEnvs: []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+		},
+		{
+			name:        "should remove a recipient from an env file",
+			args:        newArgs([]string{"recipients", "-f", encryptFilePath, "remove", identityRecipient}),
+			initialEnvs: []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "other-identity.txt")},
+		},
+		{
+			name:        "should return an error when loading an env file using an identity which was removed",
+			args:        newArgs([]string{"-f", encryptFilePath, "get", "SECRET"}),
+			initialEnvs: []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+			expectedErr: fmt.Errorf("error: cannot decrypt file '%s'.\nnone of the identities is a recipient of the file", encryptFilePath),
+		},
+		{
+			name:        "should return an error when removing every recipient of an env file",
+			args:        newArgs([]string{"recipients", "-f", encryptFilePath, "remove", otherRecipient}),
+			initialEnvs: []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "other-identity.txt")},
+			expectedErr: fmt.Errorf("error: cannot remove every recipient of file '%s'", encryptFilePath),
+		},
+		{
+			name:        "should return an error when removing an unknown recipient",
+			args:        newArgs([]string{"recipients", "-f", encryptFilePath, "remove", identityRecipient}),
+			initialEnvs: []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "other-identity.txt")},
+			expectedErr: fmt.Errorf("error: '%s' is not a recipient of file '%s'", identityRecipient, encryptFilePath),
+		},
+		{
+			name:        "should return an error for an unsupported recipients action",
+			args:        newArgs([]string{"recipients", "-f", encryptFilePath, "list"}),
+			expectedErr: fmt.Errorf("error: action 'list' is not supported, use 'enve recipients add|remove RECIPIENT...'"),
+		},
+		{
+			name:         "should print an env file decrypted using an identity",
+			args:         newArgs([]string{"decrypt", "--stdout", encryptFilePath}),
+			initialEnvs:  []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "other-identity.txt")},
+			expectedText: []string{"SECRET=\"s3cr3t value\"\n"},
+		},
+		{
+			name: "should load a dotenv-vault file using the key variable",
+			args: newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "vault", ".env.vault")}),
+			initialEnvs: []string{
+				"DOTENV_KEY=dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault?environment=development",
+			},
+			expectedText: []string{"ALPHA=zeta\n"},
+		},
+		{
+			name: "should get a variable from the production environment of a dotenv-vault file",
+			args: newArgs([]string{"-f", filepath.Join(baseDirPath, "fixtures", "vault", ".env.vault"), "get", "URL"}),
+			initialEnvs: []string{
+				"DOTENV_KEY=dotenv://:key_7f3e2a9c1b5d8e4f6a0c3b2d1e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f@dotenv.org/vault/.env.vault?environment=production",
+			},
+			expectedText: []string{"https://api.example.com:443\n"},
+		},
+		{
+			name: "should return an error when loading a dotenv-vault file using a key of a missing environment",
+			args: newArgs([]string{"-n", "-f", filepath.Join(baseDirPath, "fixtures", "vault", ".env.vault")}),
+			initialEnvs: []string{
+				"DOTENV_KEY=dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault?environment=ci",
+			},
+			expectedErr: fmt.Errorf("error: cannot decrypt vault file '%s'.\nenvironment 'ci' cannot be found in the vault (DOTENV_VAULT_CI)", filepath.Join(baseDirPath, "fixtures", "vault", ".env.vault")),
+		},
+		{
+			name:        "should encrypt an env file to rekey",
+			args:        newArgs([]string{"encrypt", filepath.Join(rekeyDirPath, "a.env")}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:        "should encrypt the values of an env file to rekey",
+			args:        newArgs([]string{"encrypt", "--values-only", filepath.Join(rekeyDirPath, "b.env")}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+		},
+		{
+			name:         "should verify the key of the encrypted env files of a directory",
+			args:         newArgs([]string{"rekey", "--dry-run", rekeyDirPath}),
+			initialEnvs:  []string{"ENVE_KEY=enve"},
+			expectedText: []string{filepath.Join(rekeyDirPath, "a.env") + "\n" + filepath.Join(rekeyDirPath, "b.env") + "\n"},
+		},
+		{
+			name:        "should return an error when rekeying a directory using a wrong key",
+			args:        newArgs([]string{"rekey", rekeyDirPath}),
+			initialEnvs: []string{"ENVE_KEY=wrong", "ENVE_NEW_KEY=new"},
+			expectedErr: fmt.Errorf("error: cannot decrypt file '%s'.\nthe passphrase is wrong or the file was modified", filepath.Join(rekeyDirPath, "a.env")),
+		},
+		{
+			name:        "should return an error when rekeying using the same passphrase",
+			args:        newArgs([]string{"rekey", rekeyDirPath}),
+			initialEnvs: []string{"ENVE_KEY=enve", "ENVE_NEW_KEY=enve"},
+			expectedErr: fmt.Errorf("error: new passphrase of file '%s' is the same as the current one", filepath.Join(rekeyDirPath, "a.env")),
+		},
+		{
+			name:        "should return an error when rekeying without a new passphrase",
+			args:        newArgs([]string{"rekey", rekeyDirPath}),
+			initialEnvs: []string{"ENVE_KEY=enve", "ENVE_NEW_KEY="},
+			expectedErr: fmt.Errorf("error: no new passphrase for file '%s', provide it via ENVE_NEW_KEY or --new-key-file", filepath.Join(rekeyDirPath, "a.env")),
+		},
+		{
+			name:        "should rekey the encrypted env files of a directory",
+			args:        newArgs([]string{"rekey", rekeyDirPath}),
+			initialEnvs: []string{"ENVE_KEY=enve", "ENVE_NEW_KEY=new"},
+		},
+		{
+			name:        "should return an error when loading a rekeyed env file using the old key",
+			args:        newArgs([]string{"-f", filepath.Join(rekeyDirPath, "a.env"), "get", "SECRET"}),
+			initialEnvs: []string{"ENVE_KEY=enve"},
+			expectedErr: fmt.Errorf("error: cannot decrypt file '%s'.\nthe passphrase is wrong or the file was modified", filepath.Join(rekeyDirPath, "a.env")),
+		},
+		{
+			name:         "should get a variable from a rekeyed env file using the new key",
+			args:         newArgs([]string{"-f", filepath.Join(rekeyDirPath, "b.env"), "get", "SECRET"}),
+			initialEnvs:  []string{"ENVE_KEY=new"},
+			expectedText: []string{"s3cr3t\n"},
+		},
+		{
+			name:        "should return an error when rekeying encrypted values for recipients",
+			args:        newArgs([]string{"rekey", "--recipient", identityRecipient, filepath.Join(rekeyDirPath, "b.env")}),
+			initialEnvs: []string{"ENVE_KEY=new"},
+			expectedErr: fmt.Errorf("error: file '%s' has encrypted values which cannot be encrypted for recipients", filepath.Join(rekeyDirPath, "b.env")),
+		},
+		{
+			name:        "should rekey an env file for recipients",
+			args:        newArgs([]string{"rekey", "--recipient", identityRecipient, filepath.Join(rekeyDirPath, "a.env")}),
+			initialEnvs: []string{"ENVE_KEY=new"},
+		},
+		{
+			name:         "should get a variable from an env file rekeyed for recipients",
+			args:         newArgs([]string{"-f", filepath.Join(rekeyDirPath, "a.env"), "get", "SECRET"}),
+			initialEnvs:  []string{"ENVE_IDENTITY=" + filepath.Join(baseDirPath, "fixtures", "crypt", "identity.txt")},
+			expectedText: []string{"s3cr3t\n"},
+		},
+		{
+			name:        "should return an error when rekeying a plain env file",
+			args:        newArgs([]string{"rekey", filepath.Join(rekeyDirPath, "plain.env")}),
+			expectedErr: fmt.Errorf("error: file '%s' is not encrypted", filepath.Join(rekeyDirPath, "plain.env")),
+		},
+		{
+			name: "should load a signed env file verifying its signature",
+			args: newArgs([]string{
+				"-n", "--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"),
+				"-f", filepath.Join(baseDirPath, "fixtures", "sign", "signed.env"),
+			}),
+			expectedText: []string{"HOST=api.example.com\n", "PORT=443\n"},
+		},
+		{
+			name: "should return an error when loading a signed env file using another public key",
+			args: newArgs([]string{
+				"-n", "--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "other.pub"),
+				"-f", filepath.Join(baseDirPath, "fixtures", "sign", "signed.env"),
+			}),
+			expectedErr: fmt.Errorf("error: cannot verify the signature of env file '%s'.\nsignature does not match the file contents", filepath.Join(baseDirPath, "fixtures", "sign", "signed.env")),
+		},
+		{
+			name: "should return an error when loading an unsigned env file verifying its signature",
+			args: newArgs([]string{
+				"-n", "--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"),
+				"-f", filepath.Join(fixturePath, validEnvFile),
+			}),
+			expectedErr: fmt.Errorf("error: env file '%[1]s' is not signed, signature file '%[1]s.sig' was not found", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name: "should return an error when verifying signatures using an invalid public key",
+			args: newArgs([]string{
+				"-n", "--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.key"),
+				"-f", filepath.Join(baseDirPath, "fixtures", "sign", "signed.env"),
+			}),
+			expectedErr: fmt.Errorf("error: public key file '%s' is invalid.\npublic key is not a PEM encoded PKIX key", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.key")),
+		},
+		{
+			name: "should return an error when verifying signatures along with stdin",
+			args: newArgs([]string{
+				"--stdin", "--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"),
+				"-n", "--output", "text",
+			}),
+			expectedErr: errors.New("error: flag '--verify-with' cannot be used along with '--stdin'"),
+		},
+		{
+			name:        "should return an error when signing without a private key",
+			args:        newArgs([]string{"sign", signFilePath}),
+			expectedErr: fmt.Errorf("error: private key was not provided, use the '--key' flag"),
+		},
+		{
+			name: "should sign an env file",
+			args: newArgs([]string{"sign", "--key", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.key"), signFilePath}),
+		},
+		{
+			name: "should get a variable from a signed env file verifying its signature",
+			args: newArgs([]string{
+				"--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"),
+				"-f", signFilePath, "get", "HOST",
+			}),
+			expectedText: []string{"localhost\n"},
+		},
+		{
+			name: "should modify a signed env file",
+			args: newArgs([]string{"set", "-f", signFilePath, "HOST=example.com"}),
+		},
+		{
+			name: "should return an error when loading a signed env file which was modified",
+			args: newArgs([]string{
+				"--verify-with", filepath.Join(baseDirPath, "fixtures", "sign", "ed25519.pub"),
+				"-f", signFilePath, "get", "HOST",
+			}),
+			expectedErr: fmt.Errorf("error: cannot verify the signature of env file '%s'.\nsignature does not match the file contents", signFilePath),
+		},
+		{
+			name:         "should get a secret reference as is",
+			args:         newArgs([]string{"-f", secretRefsFilePath, "get", "DB_PASSWORD"}),
+			expectedText: []string{"ref+file://" + filepath.Join(secretDirPath, "db") + "\n"},
+		},
+		{
+			name:         "should print the secret references of an env file as is",
+			args:         newArgs([]string{"convert", "--from", "dotenv", "--to", "json", secretRefsFilePath}),
+			expectedText: []string{"ref+exec://echo t0k3n"},
+		},
+		{
+			name:        "should return an error when resolving an exec secret reference without allowing it",
+			args:        newArgs([]string{"-n", "-f", secretRefsFilePath}),
+			initialEnvs: []string{"ENVE_TEST_OTHER=other"},
+			expectedErr: errors.New("error: cannot resolve the secret reference of variable 'TOKEN'.\nexec references run shell commands, allow them via '--allow-exec-refs'"),
+		},
+		{
+			name:         "should load an env file resolving its secret references",
+			args:         newArgs([]string{"-n", "--allow-exec-refs", "-f", secretRefsFilePath}),
+			initialEnvs:  []string{"ENVE_TEST_OTHER=other"},
+			expectedText: []string{"DB_PASSWORD=s3cr3t\n", "TOKEN=t0k3n\n", "OTHER=other\n"},
+		},
+		{
+			name:        "should return an error naming the variable of a secret reference which cannot be resolved",
+			args:        newArgs([]string{"-n", "-f", brokenRefsFilePath}),
+			expectedErr: fmt.Errorf("error: cannot resolve the secret reference of variable 'OTHER'.\nvariable 'ENVE_TEST_MISSING' is not set"),
+		},
+		{
+			name:        "should return an error when listing the recipients of a plain env file",
+			args:        newArgs([]string{"recipients", "-f", filepath.Join(fixturePath, validEnvFile)}),
+			expectedErr: fmt.Errorf("error: file '%s' is not encrypted for recipients, use 'enve encrypt --recipient'", filepath.Join(fixturePath, validEnvFile)),
+		},
+		{
+			name:        "should return an error when formatting an invalid env file",
+			args:        newArgsDefaultInvalid([]string{"fmt"}),
+			expectedErr: fmt.Errorf("error: cannot format file '%s'.", filepath.Join(fixturePath, invalidEnvFile)),
+		},
+		{
+			name: "should execute the command once per matrix env file",
+			args: newArgs([]string{
+				"--matrix", filepath.Join(fixturePath, "valid.env") + "," + filepath.Join(fixturePath, ".env"),
+				"-n", "-x", "echo", "${HOST}${SERVER}",
+			}),
+			expectedText: []string{"127.0.0.1\nlocalhost\n"},
+		},
+		{
+			name: "should return an error when a matrix run fails",
+			args: newArgs([]string{
+				"--matrix", filepath.Join(fixturePath, "*.env"), "--parallel", "2", "echo", "hello",
+			}),
+			expectedErr: errors.New("error: 1 of 3 matrix runs failed"),
+		},
+		{
+			name:        "should return an error when using matrix without a command",
+			args:        newArgs([]string{"--matrix", "dev.env"}),
+			expectedErr: errors.New("error: flag '--matrix' requires a command to execute"),
+		},
+		{
+			name:        "should return an error when using matrix along with stdin",
+			args:        newArgs([]string{"--matrix", "dev.env", "--stdin", "echo", "hello"}),
+			expectedErr: errors.New("error: flag '--matrix' cannot be used along with"),
+		},
+		{
+			name:        "should return an error for an invalid number of parallel runs",
+			args:        newArgs([]string{"--matrix", "dev.env", "--parallel", "0", "echo", "hello"}),
+			expectedErr: errors.New("error: invalid number of parallel runs '0' for flag '--parallel'"),
+		},
 		{
 			name:        "should return an error invalid output format",
 			args:        newArgs([]string{"--output", "xyz"}),
@@ -412,6 +1393,7 @@ func TestAppHandler_Output(t *testing.T) {
 			ap.Summary = "Run a program in a modified environment"
 			ap.Version = "v1.0.0-beta.1"
 			ap.Flags = Flags
+			ap.Commands = Commands
 			ap.Handler = appHandler
 
 			if tt.initialEnvs != nil {
@@ -477,6 +1459,11 @@ func TestAppHandler_Output(t *testing.T) {
 			if tt.expectedErr != nil {
 				assert.Error(t, runErr, "Expected error but got none")
 				assert.Contains(t, runErr.Error(), tt.expectedErr.Error(), "Error message mismatch")
+				if tt.expectedCode > 0 {
+					var exitErr *ExitError
+					assert.ErrorAs(t, runErr, &exitErr)
+					assert.Equal(t, tt.expectedCode, exitErr.Code, "Exit code mismatch")
+				}
 			} else {
 				assert.NoError(t, runErr, "Expected no error but got: %v", runErr)
 			}
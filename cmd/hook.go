@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/trust"
+)
+
+// hookStateVar is the variable keeping the state of the env file loaded by the shell hook.
+const hookStateVar = "ENVE_HOOK"
+
+// varNameRegex matches the variable names that can be exported by a shell.
+var varNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// hookState describes the env file loaded by the shell hook and how to revert it.
+type hookState struct {
+	// Absolute path of the loaded env file.
+	File string `json:"file"`
+	// Content stamp of the loaded env file.
+	Stamp string `json:"stamp"`
+	// Values of the variables before loading, nil when not set.
+	Prev map[string]*string `json:"prev"`
+}
+
+// encode returns the state as a string which can be safely stored in an environment variable.
+func (s *hookState) encode() (string, error) {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// decodeHookState parses a state previously encoded, nil is returned for an empty one.
+func decodeHookState(value string) (*hookState, error) {
+	if value == "" {
+		return nil, nil
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("error: invalid %s state.\n%v", hookStateVar, err)
+	}
+	var s hookState
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return nil, fmt.Errorf("error: invalid %s state.\n%v", hookStateVar, err)
+	}
+	return &s, nil
+}
+
+// revert returns the given environment restoring the variables changed by the state.
+func (s *hookState) revert(vars env.Map) env.Map {
+	reverted := env.Map{}
+	for k, v := range vars {
+		reverted[k] = v
+	}
+	if s == nil {
+		return reverted
+	}
+	for k, v := range s.Prev {
+		if v == nil {
+			delete(reverted, k)
+		} else {
+			reverted[k] = *v
+		}
+	}
+	return reverted
+}
+
+// apply returns the given environment with the file variables applied on top
+// along with the previous values of the variables it changed.
+func apply(vars env.Map, fileVars env.Map, overwrite bool) (env.Map, map[string]*string) {
+	applied := env.Map{}
+	for k, v := range vars {
+		applied[k] = v
+	}
+	prev := map[string]*string{}
+	for k, v := range fileVars {
+		old, ok := vars[k]
+		if ok && (!overwrite || old == v) {
+			continue
+		}
+		if ok {
+			prev[k] = &old
+		} else {
+			prev[k] = nil
+		}
+		applied[k] = v
+	}
+	return applied, prev
+}
+
+// hookShell describes how a supported shell installs the hook and modifies its environment.
+type hookShell interface {
+	hook(self string) string
+	export(name, value string) string
+	unset(name string) string
+}
+
+var hookShells = map[string]hookShell{
+	"bash": bashShell{},
+	"zsh":  zshShell{},
+	"fish": fishShell{},
+}
+
+// posixShell modifies the environment of POSIX compatible shells.
+type posixShell struct{}
+
+func (posixShell) export(name, value string) string {
+	return "export " + name + "='" + strings.ReplaceAll(value, "'", `'\''`) + "';"
+}
+
+func (posixShell) unset(name string) string {
+	return "unset " + name + ";"
+}
+
+type bashShell struct{ posixShell }
+
+func (bashShell) hook(self string) string {
+	return `_enve_hook() {
+  local previous_exit_status=$?
+  trap -- '' SIGINT
+  eval "$(` + self + ` export bash)"
+  trap - SIGINT
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_enve_hook;"* ]]; then
+  PROMPT_COMMAND="_enve_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+}
+
+type zshShell struct{ posixShell }
+
+func (zshShell) hook(self string) string {
+	return `_enve_hook() {
+  trap -- '' SIGINT
+  eval "$(` + self + ` export zsh)"
+  trap - SIGINT
+}
+typeset -ag precmd_functions chpwd_functions
+if (( ! ${precmd_functions[(I)_enve_hook]} )); then
+  precmd_functions=(_enve_hook $precmd_functions)
+fi
+if (( ! ${chpwd_functions[(I)_enve_hook]} )); then
+  chpwd_functions=(_enve_hook $chpwd_functions)
+fi
+`
+}
+
+// fishShell modifies the environment of the fish shell.
+type fishShell struct{}
+
+func (fishShell) hook(self string) string {
+	return `function __enve_export_eval --on-event fish_prompt
+    ` + self + ` export fish | source
+end
+function __enve_cd_hook --on-variable PWD
+    ` + self + ` export fish | source
+end
+`
+}
+
+func (fishShell) export(name, value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "'", `\'`)
+	return "set -gx " + name + " '" + value + "';"
+}
+
+func (fishShell) unset(name string) string {
+	return "set -e " + name + ";"
+}
+
+// shellQuote quotes a single word for POSIX shells and fish.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// lookupShell returns the shell given as the first tail argument.
+func lookupShell(tailArgs []string) (hookShell, error) {
+	if len(tailArgs) == 0 {
+		return nil, fmt.Errorf("error: shell name was not provided, use one of bash, zsh or fish")
+	}
+	name := tailArgs[0]
+	sh, ok := hookShells[name]
+	if !ok {
+		return nil, fmt.Errorf("error: shell '%s' is not supported, use one of bash, zsh or fish", name)
+	}
+	return sh, nil
+}
+
+// findEnvFile looks up the env file in the current working directory and its parents.
+// An absolute file path is returned as is when it exists.
+func findEnvFile(filePath string) string {
+	if filepath.IsAbs(filePath) {
+		if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+			return filePath
+		}
+		return ""
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, filePath)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// writeExport writes the commands turning the current environment into the target one.
+func writeExport(w io.Writer, sh hookShell, current, target env.Map) {
+	names := []string{}
+	for k := range current {
+		names = append(names, k)
+	}
+	for k := range target {
+		if _, ok := current[k]; !ok {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		if k == hookStateVar {
+			continue
+		}
+		val, ok := target[k]
+		if !ok {
+			fmt.Fprintln(w, sh.unset(k))
+			continue
+		}
+		if old, found := current[k]; found && old == val {
+			continue
+		}
+		fmt.Fprintln(w, sh.export(k, val))
+	}
+}
+
+// hookHandler prints the script installing the hook for the given shell.
+func hookHandler(ctx *app.CmdContext) error {
+	sh, err := lookupShell(ctx.TailArgs)
+	if err != nil {
+		return err
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{shellQuote(self)}
+
+	flags := ctx.AppContext.Flags()
+	// file option
+	fileF, err := flags.String("file")
+	if err != nil {
+		return err
+	}
+	if fileF.IsProvided() {
+		args = append(args, "--file", shellQuote(fileF.Value()))
+	}
+	// overwrite option
+	overwriteF, err := flags.Bool("overwrite")
+	if err != nil {
+		return err
+	}
+	if overwrite, err := overwriteF.Value(); err != nil {
+		return err
+	} else if overwrite {
+		args = append(args, "--overwrite")
+	}
+	// allow-exec-refs option
+	allowExecRefsF, err := flags.Bool("allow-exec-refs")
+	if err != nil {
+		return err
+	}
+	if allowExecRefs, err := allowExecRefsF.Value(); err != nil {
+		return err
+	} else if allowExecRefs {
+		args = append(args, "--allow-exec-refs")
+	}
+	// verify-with option
+	verifyWithF, err := flags.String("verify-with")
+	if err != nil {
+		return err
+	}
+	if verifyWithF.IsProvided() {
+		// NOTE: the hook runs from any directory so the public key path is made absolute
+		keyPath, err := filepath.Abs(verifyWithF.Value())
+		if err != nil {
+			return err
+		}
+		args = append(args, "--verify-with", shellQuote(keyPath))
+	}
+
+	fmt.Print(sh.hook(strings.Join(args, " ")))
+	return nil
+}
+
+// exportHandler prints the commands loading the env file found for the current directory
+// and unloading the previous one if any.
+func exportHandler(ctx *app.CmdContext) error {
+	sh, err := lookupShell(ctx.TailArgs)
+	if err != nil {
+		return err
+	}
+
+	environ, err := newEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+	// NOTE: env files get loaded on directory change, including the ones of parent directories,
+	// so only trusted files are loaded
+	environ.requireTrust = true
+	current := environ.baseEnv.Map()
+	state, err := decodeHookState(current[hookStateVar])
+	if err != nil {
+		// NOTE: an invalid state cannot be reverted, so start from scratch
+		fmt.Fprintf(os.Stderr, "enve: %v\n", err)
+		state = nil
+	}
+
+	var buf []byte
+	var fileStamp string
+	file := findEnvFile(environ.filePath)
+	if file != "" {
+		buf, err = environ.readFile(file)
+		var trustErr *trustError
+		switch {
+		case errors.As(err, &trustErr):
+			// NOTE: untrusted files are not loaded but the previous one still gets unloaded
+			fmt.Fprintf(os.Stderr, "enve: %v\n", err)
+			file = ""
+		case err != nil:
+			return err
+		default:
+			// NOTE: same fingerprint as the stamp of a file but using the content which gets loaded
+			fileStamp = trust.HashContent(buf)
+		}
+	}
+	if state == nil && file == "" {
+		return nil
+	}
+	if state != nil && file == state.File && fileStamp == state.Stamp {
+		return nil
+	}
+
+	target := state.revert(current)
+	if state != nil {
+		fmt.Fprintf(os.Stderr, "enve: unloading %s\n", state.File)
+	}
+
+	var next *hookState
+	if file != "" {
+		envf, err := env.FromBytes(file, buf)
+		if err != nil {
+			return err
+		}
+		defer envf.Close()
+		fileVars, err := environ.parseVars(envf)
+		if err != nil {
+			return fmt.Errorf("error: cannot load env file '%s'.\n%v", file, err)
+		}
+		for k := range fileVars {
+			if !varNameRegex.MatchString(k) || k == hookStateVar {
+				fmt.Fprintf(os.Stderr, "enve: skipping invalid variable name '%s'\n", k)
+				delete(fileVars, k)
+			}
+		}
+
+		var prev map[string]*string
+		target, prev = apply(target, fileVars, environ.overwrite)
+		next = &hookState{File: file, Stamp: fileStamp, Prev: prev}
+		fmt.Fprintf(os.Stderr, "enve: loading %s\n", file)
+	}
+
+	writeExport(os.Stdout, sh, current, target)
+	if next == nil {
+		fmt.Println(sh.unset(hookStateVar))
+		return nil
+	}
+	encoded, err := next.encode()
+	if err != nil {
+		return err
+	}
+	fmt.Println(sh.export(hookStateVar, encoded))
+	return nil
+}
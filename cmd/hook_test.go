@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_hookState(t *testing.T) {
+	t.Run("should encode and decode a state", func(t *testing.T) {
+		old := "orig"
+		state := &hookState{File: "/app/.env", Stamp: "abc", Prev: map[string]*string{"FOO": &old, "BAR": nil}}
+		encoded, err := state.encode()
+		assert.NoError(t, err)
+		assert.NotContains(t, encoded, "'")
+
+		decoded, err := decodeHookState(encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, state, decoded)
+	})
+
+	t.Run("should return no state for an empty value", func(t *testing.T) {
+		decoded, err := decodeHookState("")
+		assert.NoError(t, err)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("should return an error for an invalid state", func(t *testing.T) {
+		_, err := decodeHookState("%%%")
+		assert.ErrorContains(t, err, "error: invalid ENVE_HOOK state.")
+	})
+
+	t.Run("should apply and revert the file variables exactly", func(t *testing.T) {
+		vars := env.Map{"FOO": "orig", "HOME": "/root"}
+		fileVars := env.Map{"FOO": "bar", "BAR": "baz", "HOME": "/root"}
+
+		applied, prev := apply(vars, fileVars, true)
+		assert.Equal(t, env.Map{"FOO": "bar", "BAR": "baz", "HOME": "/root"}, applied)
+
+		old := "orig"
+		assert.Equal(t, map[string]*string{"FOO": &old, "BAR": nil}, prev)
+
+		state := &hookState{Prev: prev}
+		assert.Equal(t, vars, state.revert(applied))
+	})
+
+	t.Run("should not replace existing variables without overwrite", func(t *testing.T) {
+		applied, prev := apply(env.Map{"FOO": "orig"}, env.Map{"FOO": "bar", "BAR": "baz"}, false)
+		assert.Equal(t, env.Map{"FOO": "orig", "BAR": "baz"}, applied)
+		assert.Equal(t, map[string]*string{"BAR": nil}, prev)
+	})
+}
+
+func Test_writeExport(t *testing.T) {
+	current := env.Map{"FOO": "orig", "OLD": "1", "SAME": "x", hookStateVar: "state"}
+	target := env.Map{"FOO": "it's", "NEW": `a\b`, "SAME": "x"}
+
+	tests := []struct {
+		name     string
+		shell    string
+		expected string
+	}{
+		{
+			name:     "should write bash commands",
+			shell:    "bash",
+			expected: "export FOO='it'\\''s';\nexport NEW='a\\b';\nunset OLD;\n",
+		},
+		{
+			name:     "should write zsh commands",
+			shell:    "zsh",
+			expected: "export FOO='it'\\''s';\nexport NEW='a\\b';\nunset OLD;\n",
+		},
+		{
+			name:     "should write fish commands",
+			shell:    "fish",
+			expected: "set -gx FOO 'it\\'s';\nset -gx NEW 'a\\\\b';\nset -e OLD;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeExport(&buf, hookShells[tt.shell], current, target)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func Test_lookupShell(t *testing.T) {
+	_, err := lookupShell(nil)
+	assert.EqualError(t, err, "error: shell name was not provided, use one of bash, zsh or fish")
+
+	_, err = lookupShell([]string{"tcsh"})
+	assert.Equal(t, errors.New("error: shell 'tcsh' is not supported, use one of bash, zsh or fish"), err)
+
+	sh, err := lookupShell([]string{"fish"})
+	assert.NoError(t, err)
+	assert.Equal(t, fishShell{}, sh)
+}
+
+func Test_findEnvFile(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() { _ = os.Chdir(cwd) }()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	sub := filepath.Join(root, "a", "b")
+	assert.NoError(t, os.MkdirAll(sub, 0755))
+	envPath := filepath.Join(root, "a", ".env")
+	assert.NoError(t, os.WriteFile(envPath, []byte("FOO=bar\n"), 0644))
+
+	assert.NoError(t, os.Chdir(sub))
+	assert.Equal(t, envPath, findEnvFile(".env"))
+	assert.Equal(t, envPath, findEnvFile(envPath))
+	assert.Equal(t, "", findEnvFile("missing.env"))
+
+	assert.NoError(t, os.Chdir(root))
+	assert.Equal(t, "", findEnvFile(".env"))
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/fs"
+)
+
+// lintFinding is a lint finding of an env file.
+type lintFinding struct {
+	File string `json:"file" xml:"file,attr"`
+	dotenv.Finding
+}
+
+// lintReport contains the lint findings of all checked env files.
+type lintReport struct {
+	XMLName  xml.Name      `json:"-" xml:"lint"`
+	Findings []lintFinding `json:"findings" xml:"finding"`
+}
+
+// Text returns the findings in a `file:line: severity: message (rule)` format.
+func (r *lintReport) Text() string {
+	lines := make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		lines = append(lines, fmt.Sprintf("%s:%d: %s: %s (%s)", f.File, f.Line, f.Severity, f.Message, f.Rule))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// failed reports whether there are findings other than informative ones.
+func (r *lintReport) failed() bool {
+	for _, f := range r.Findings {
+		if f.Severity != dotenv.SeverityInfo {
+			return true
+		}
+	}
+	return false
+}
+
+// sarifLevel returns the SARIF level of a severity.
+func sarifLevel(s dotenv.Severity) string {
+	if s == dotenv.SeverityInfo {
+		return "note"
+	}
+	return string(s)
+}
+
+// SARIF returns the findings as a SARIF 2.1.0 log for code scanning tools.
+func (r *lintReport) SARIF() ([]byte, error) {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID                   string            `json:"id"`
+		ShortDescription     message           `json:"shortDescription"`
+		DefaultConfiguration map[string]string `json:"defaultConfiguration"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	rules := []rule{}
+	for _, r := range dotenv.Rules {
+		rules = append(rules, rule{
+			ID:                   r.ID,
+			ShortDescription:     message{Text: r.Description},
+			DefaultConfiguration: map[string]string{"level": sarifLevel(r.Severity)},
+		})
+	}
+	results := []result{}
+	for _, f := range r.Findings {
+		var loc location
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(f.File)
+		loc.PhysicalLocation.Region.StartLine = f.Line
+		results = append(results, result{
+			RuleID:    f.Rule,
+			Level:     sarifLevel(f.Severity),
+			Message:   message{Text: f.Message},
+			Locations: []location{loc},
+		})
+	}
+
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{
+			map[string]any{
+				"tool": map[string]any{
+					"driver": map[string]any{
+						"name":           "enve",
+						"informationUri": "https://github.com/joseluisq/enve",
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// lintHandler checks the given env files (or the one of the `--file` flag) for common problems.
+func lintHandler(ctx *app.CmdContext) error {
+	// disable option
+	disableF, err := ctx.Flags.StringSlice("disable")
+	if err != nil {
+		return err
+	}
+	var disabled []string
+	for _, id := range disableF.Value() {
+		if id == "" {
+			continue
+		}
+		if _, ok := dotenv.LookupRule(id); !ok {
+			return fmt.Errorf("error: lint rule '%s' is not supported", id)
+		}
+		disabled = append(disabled, id)
+	}
+
+	// output option
+	outputF, err := ctx.Flags.String("output")
+	if err != nil {
+		return err
+	}
+
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := &lintReport{Findings: []lintFinding{}}
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", file, err)
+		}
+		for _, f := range dotenv.Lint(buf, disabled...) {
+			report.Findings = append(report.Findings, lintFinding{File: file, Finding: f})
+		}
+	}
+
+	if out := outputF.Value(); out == "sarif" {
+		buf, err := report.SARIF()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(buf))
+	} else if len(report.Findings) > 0 || out != "text" {
+		if err := printOutput(out, report.Text(), report); err != nil {
+			return err
+		}
+	}
+
+	if report.failed() {
+		return fmt.Errorf("error: found %d problems", len(report.Findings))
+	}
+	return nil
+}
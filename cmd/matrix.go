@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// matrixResult holds the outcome of running a command using an env file.
+type matrixResult struct {
+	file     string
+	err      error
+	duration time.Duration
+}
+
+// expandMatrix resolves a list of env file paths or glob patterns into unique file paths.
+func expandMatrix(patterns []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		matches := []string{p}
+		if strings.ContainsAny(p, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(p); err != nil {
+				return nil, fmt.Errorf("error: invalid matrix pattern '%s'.\n%v", p, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("error: no env files match the matrix pattern '%s'", p)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("error: no env files were provided for the matrix")
+	}
+	return files, nil
+}
+
+// runMatrix runs a command once per env file keeping up to `parallel` runs at the same time.
+// The output of every run is prefixed with its env file when running in parallel.
+func runMatrix(files []string, parallel int, out io.Writer, errOut io.Writer, run func(file string, stdout io.Writer, stderr io.Writer) error) []matrixResult {
+	results := make([]matrixResult, len(files))
+
+	width := 0
+	for _, f := range files {
+		width = max(width, len(f))
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	color := useColors(out)
+	sem := make(chan struct{}, max(parallel, 1))
+	for i, file := range files {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stdout, stderr := out, errOut
+			var w *prefixWriter
+			if parallel > 1 {
+				w = newPrefixWriter(&mu, out, formatPrefix(file, width, i, color))
+				stdout, stderr = w, w
+			}
+
+			start := time.Now()
+			err := run(file, stdout, stderr)
+			if w != nil {
+				_ = w.Flush()
+			}
+			results[i] = matrixResult{file: file, err: err, duration: time.Since(start)}
+		}(i, file)
+	}
+	wg.Wait()
+	return results
+}
+
+// printMatrixSummary writes a table containing the exit code and duration of every run.
+func printMatrixSummary(w io.Writer, results []matrixResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "ENV FILE\tSTATUS\tEXIT CODE\tDURATION")
+	for _, r := range results {
+		status, code := "ok", "0"
+		if r.err != nil {
+			status, code = "failed", "-"
+			if c, ok := exitCodeOf(r.err); ok {
+				code = strconv.Itoa(c)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.file, status, code, r.duration.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
+
+// execCmdMatrix executes a command once per env file building every environment on top of the inherited one.
+func execCmdMatrix(environ *environ, files []string, tailArgs []string, expandArgs bool, parallel int, timeout time.Duration, killAfter time.Duration) error {
+	run := func(file string, stdout io.Writer, stderr io.Writer) error {
+		envVars, err := environ.fromFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "enve: %s\n", err)
+			return err
+		}
+		args := tailArgs
+		if expandArgs {
+			args = envVars.Expand(args)
+		}
+
+		std := stdio{out: stdout, err: stderr}
+		if parallel <= 1 {
+			std.in = os.Stdin
+		}
+		err = execCmdTimeoutIO(args, "", true, envVars, timeout, killAfter, std)
+		if _, ok := exitCodeOf(err); err != nil && !ok {
+			fmt.Fprintf(stderr, "enve: %s\n", err)
+		}
+		return err
+	}
+
+	results := runMatrix(files, parallel, os.Stdout, os.Stderr, run)
+	if err := printMatrixSummary(os.Stderr, results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("error: %d of %d matrix runs failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_expandMatrix(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"dev.env", "prod.env", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("KEY=VALUE"), 0644))
+	}
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:     "should expand glob patterns",
+			patterns: []string{filepath.Join(dir, "*.env")},
+			expected: []string{filepath.Join(dir, "dev.env"), filepath.Join(dir, "prod.env")},
+		},
+		{
+			name:     "should keep plain paths and remove duplicates",
+			patterns: []string{filepath.Join(dir, "prod.env"), "", filepath.Join(dir, "*.env"), "missing.env"},
+			expected: []string{filepath.Join(dir, "prod.env"), filepath.Join(dir, "dev.env"), "missing.env"},
+		},
+		{
+			name:        "should return an error when a pattern does not match",
+			patterns:    []string{filepath.Join(dir, "*.yml")},
+			expectedErr: fmt.Errorf("error: no env files match the matrix pattern '%s'", filepath.Join(dir, "*.yml")),
+		},
+		{
+			name:        "should return an error when no files are provided",
+			patterns:    []string{""},
+			expectedErr: errors.New("error: no env files were provided for the matrix"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := expandMatrix(tt.patterns)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_runMatrix(t *testing.T) {
+	files := []string{"dev.env", "staging.env", "prod.env"}
+	run := func(file string, stdout io.Writer, stderr io.Writer) error {
+		fmt.Fprintf(stdout, "running %s\n", file)
+		if file == "prod.env" {
+			return exec.Command("sh", "-c", "exit 2").Run()
+		}
+		return nil
+	}
+
+	t.Run("should run sequentially keeping the file order", func(t *testing.T) {
+		var out bytes.Buffer
+		results := runMatrix(files, 1, &out, io.Discard, run)
+
+		assert.Equal(t, "running dev.env\nrunning staging.env\nrunning prod.env\n", out.String())
+		assert.Len(t, results, 3)
+		assert.NoError(t, results[0].err)
+		assert.NoError(t, results[1].err)
+		assert.EqualError(t, results[2].err, "exit status 2")
+	})
+
+	t.Run("should prefix the output when running in parallel", func(t *testing.T) {
+		var out bytes.Buffer
+		results := runMatrix(files, 2, &out, io.Discard, run)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		sort.Strings(lines)
+		assert.Equal(t, []string{
+			"dev.env     | running dev.env",
+			"prod.env    | running prod.env",
+			"staging.env | running staging.env",
+		}, lines)
+		assert.Equal(t, "prod.env", results[2].file, "results should keep the file order")
+	})
+}
+
+func Test_printMatrixSummary(t *testing.T) {
+	t.Run("should print a summary table", func(t *testing.T) {
+		results := []matrixResult{
+			{file: "dev.env", duration: 1500 * time.Microsecond},
+			{file: "prod.env", err: exec.Command("sh", "-c", "exit 3").Run(), duration: time.Second},
+			{file: "missing.env", err: errors.New("error: cannot access file 'missing.env'.")},
+		}
+
+		var out bytes.Buffer
+		assert.NoError(t, printMatrixSummary(&out, results))
+		assert.Equal(t, ""+
+			"ENV FILE      STATUS   EXIT CODE   DURATION\n"+
+			"dev.env       ok       0           2ms\n"+
+			"prod.env      failed   3           1s\n"+
+			"missing.env   failed   -           0s\n", out.String())
+	})
+}
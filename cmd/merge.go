@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/fs"
+)
+
+// mergeHandler merges the given env files into the first one keeping its comments and formatting.
+func mergeHandler(ctx *app.CmdContext) error {
+	// prefer option
+	preferF, err := ctx.Flags.String("prefer")
+	if err != nil {
+		return err
+	}
+	prefer := dotenv.Prefer(preferF.Value())
+	switch prefer {
+	case dotenv.PreferLeft, dotenv.PreferRight, dotenv.PreferError:
+	default:
+		return fmt.Errorf("error: merge strategy '%s' is not supported, use left, right or error-on-conflict", prefer)
+	}
+
+	// output option
+	outputF, err := ctx.Flags.String("output")
+	if err != nil {
+		return err
+	}
+
+	files := ctx.TailArgs
+	if len(files) < 2 {
+		return fmt.Errorf("error: at least two env files are required, use 'enve merge BASE_FILE FILE...'")
+	}
+
+	var merged *dotenv.File
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", file, err)
+		}
+		if isEncryptedFile(buf) {
+			return fmt.Errorf("error: file '%s' is encrypted, decrypt it first via 'enve decrypt'", file)
+		}
+		f := dotenv.ParseBytes(buf)
+		if merged == nil {
+			if err := f.Err(); err != nil {
+				return fmt.Errorf("error: cannot merge file '%s'.\n%v", file, err)
+			}
+			merged = f
+			continue
+		}
+		if err := merged.Merge(f, prefer); err != nil {
+			var conflictErr *dotenv.ConflictError
+			if errors.As(err, &conflictErr) {
+				return fmt.Errorf("error: cannot merge file '%s' into '%s' because of %v", file, files[0], err)
+			}
+			return fmt.Errorf("error: cannot merge file '%s'.\n%v", file, err)
+		}
+	}
+
+	if outputF.IsProvided() {
+		return fs.WriteFileAtomic(outputF.Value(), merged.Bytes(), 0644)
+	}
+	fmt.Print(string(merged.Bytes()))
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+)
+
+// printOutput prints the given text or data using the text, json or xml output format.
+func printOutput(format string, text string, data any) error {
+	switch format {
+	case "text":
+		fmt.Println(text)
+	case "json":
+		if buf, err := json.Marshal(data); err != nil {
+			return err
+		} else {
+			fmt.Println(string(buf))
+		}
+	case "xml":
+		if buf, err := xml.Marshal(data); err != nil {
+			return err
+		} else {
+			fmt.Println("<?xml version=\"1.0\" encoding=\"UTF-8\"?>" + string(buf))
+		}
+	default:
+		if format == "" {
+			return fmt.Errorf("error: output format was empty or not provided")
+		}
+		return fmt.Errorf("error: output format '%s' is not supported", format)
+	}
+
+	return nil
+}
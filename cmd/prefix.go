@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// prefixColors contains the ANSI colors used to distinguish output prefixes.
+var prefixColors = []int{36, 33, 32, 35, 34, 31}
+
+// useColors reports whether the given output supports ANSI colors.
+func useColors(out io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && (fi.Mode()&os.ModeCharDevice) != 0
+}
+
+// formatPrefix returns a label padded to the given width and optionally colored by index.
+func formatPrefix(label string, width int, index int, color bool) string {
+	prefix := fmt.Sprintf("%-*s | ", width, label)
+	if color {
+		return fmt.Sprintf("\x1b[%dm%s\x1b[0m", prefixColors[index%len(prefixColors)], prefix)
+	}
+	return prefix
+}
+
+// prefixWriter writes every line prefixed by a label into an output shared by other writers.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+// newPrefixWriter creates a new prefixWriter which serializes its writes using the given mutex.
+func newPrefixWriter(mu *sync.Mutex, out io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{mu: mu, out: out, prefix: prefix}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the remaining incomplete line if any.
+func (w *prefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return err
+}
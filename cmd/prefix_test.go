@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_formatPrefix(t *testing.T) {
+	t.Run("should pad the label to the given width", func(t *testing.T) {
+		assert.Equal(t, "web    | ", formatPrefix("web", 6, 0, false))
+	})
+
+	t.Run("should color the label by index", func(t *testing.T) {
+		assert.Equal(t, "\x1b[33mweb | \x1b[0m", formatPrefix("web", 3, 1, true))
+		assert.Equal(t, "\x1b[36mweb | \x1b[0m", formatPrefix("web", 3, len(prefixColors), true))
+	})
+}
+
+func Test_prefixWriter(t *testing.T) {
+	t.Run("should prefix every complete line", func(t *testing.T) {
+		var mu sync.Mutex
+		var out bytes.Buffer
+		w := newPrefixWriter(&mu, &out, "web | ")
+
+		n, err := w.Write([]byte("first\nsecond\nthi"))
+		assert.NoError(t, err)
+		assert.Equal(t, 16, n)
+		assert.Equal(t, "web | first\nweb | second\n", out.String())
+
+		_, err = w.Write([]byte("rd\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "web | first\nweb | second\nweb | third\n", out.String())
+	})
+
+	t.Run("should flush the remaining incomplete line", func(t *testing.T) {
+		var mu sync.Mutex
+		var out bytes.Buffer
+		w := newPrefixWriter(&mu, &out, "worker | ")
+
+		_, err := w.Write([]byte("no newline"))
+		assert.NoError(t, err)
+		assert.Equal(t, "", out.String())
+
+		assert.NoError(t, w.Flush())
+		assert.Equal(t, "worker | no newline\n", out.String())
+
+		assert.NoError(t, w.Flush())
+		assert.Equal(t, "worker | no newline\n", out.String(), "second flush should be a no-op")
+	})
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/crypt"
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// recipientsHandler lists, adds or removes the recipients of an env file encrypted for recipients
+// using the identity of ENVE_IDENTITY. Changing the recipients encrypts the file again with a new file key.
+func recipientsHandler(ctx *app.CmdContext) error {
+	filePath, err := editFilePath(ctx)
+	if err != nil {
+		return err
+	}
+
+	var action string
+	var args []string
+	if len(ctx.TailArgs) > 0 {
+		action, args = ctx.TailArgs[0], ctx.TailArgs[1:]
+		if action != "add" && action != "remove" {
+			return fmt.Errorf("error: action '%s' is not supported, use 'enve recipients add|remove RECIPIENT...'", action)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("error: no recipients to %s, use 'enve recipients %s RECIPIENT...'", action, action)
+		}
+	}
+
+	if err := fs.FileExists(filePath); err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	if !crypt.HasRecipients(buf) {
+		return fmt.Errorf("error: file '%s' is not encrypted for recipients, use 'enve encrypt --recipient'", filePath)
+	}
+	// NOTE: the recipients are part of the encrypted content, so listing them requires an identity too
+	identities, err := env.DecryptionIdentity(filePath)
+	if err != nil {
+		return err
+	}
+	current, err := crypt.Recipients(buf, identities)
+	if err != nil {
+		return fmt.Errorf("error: cannot read the recipients of file '%s'.\n%v", filePath, err)
+	}
+
+	if action == "" {
+		for _, r := range current {
+			fmt.Println(r)
+		}
+		return nil
+	}
+
+	recipients := current
+	if action == "add" {
+		recipients = append(recipients, args...)
+	} else {
+		for _, r := range args {
+			if !slices.Contains(current, r) {
+				return fmt.Errorf("error: '%s' is not a recipient of file '%s'", r, filePath)
+			}
+		}
+		recipients = slices.DeleteFunc(recipients, func(r string) bool {
+			return slices.Contains(args, r)
+		})
+		if len(recipients) == 0 {
+			return fmt.Errorf("error: cannot remove every recipient of file '%s'", filePath)
+		}
+	}
+
+	updated, err := crypt.SetRecipients(buf, identities, recipients)
+	if err != nil {
+		return fmt.Errorf("error: cannot update the recipients of file '%s'.\n%v", filePath, err)
+	}
+	return fs.WriteFileAtomic(filePath, updated, 0600)
+}
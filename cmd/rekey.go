@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/crypt"
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// newKeyEnvVar is the variable containing the new passphrase of the rekey command.
+const newKeyEnvVar = "ENVE_NEW_KEY"
+
+// rekeyFile is an encrypted env file to rekey.
+type rekeyFile struct {
+	path string
+	// Whether the file was given explicitly instead of found in a directory.
+	explicit bool
+}
+
+// rekeyFiles returns the given files along with the ones found in the given directories.
+func rekeyFiles(paths []string) ([]rekeyFile, error) {
+	var files []rekeyFile
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot access '%s'.\n%v", p, err)
+		}
+		if !info.IsDir() {
+			files = append(files, rekeyFile{path: p, explicit: true})
+			continue
+		}
+		err = filepath.WalkDir(p, func(path string, d iofs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if d.Type().IsRegular() {
+				files = append(files, rekeyFile{path: path})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read directory '%s'.\n%v", p, err)
+		}
+	}
+	return files, nil
+}
+
+// rekeyHandler decrypts the given encrypted env files (or the ones found in the given directories)
+// and encrypts them again using a new passphrase or recipients.
+// Every file is decrypted before writing any of them so a wrong key leaves all files untouched.
+func rekeyHandler(ctx *app.CmdContext) error {
+	// dry-run option
+	dryRunF, err := ctx.Flags.Bool("dry-run")
+	if err != nil {
+		return err
+	}
+	dryRun, err := dryRunF.Value()
+	if err != nil {
+		return err
+	}
+
+	// recipient option
+	recipientF, err := ctx.Flags.StringSlice("recipient")
+	if err != nil {
+		return err
+	}
+	var recipients []string
+	if recipientF.IsProvided() {
+		recipients = recipientF.Value()
+	}
+
+	// new-key-file option
+	newKeyFileF, err := ctx.Flags.String("new-key-file")
+	if err != nil {
+		return err
+	}
+	newKeys := &keySource{
+		keyFile:     newKeyFileF.Value(),
+		keyFileFlag: "--new-key-file",
+		envVar:      newKeyEnvVar,
+		label:       "new passphrase",
+	}
+
+	paths, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+	files, err := rekeyFiles(paths)
+	if err != nil {
+		return err
+	}
+	keys, err := newKeySource(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	type result struct {
+		path string
+		data []byte
+	}
+	var results []result
+	found := 0
+	for _, f := range files {
+		buf, err := os.ReadFile(f.path)
+		if err != nil {
+			return fmt.Errorf("error: cannot read file '%s'.\n%v", f.path, err)
+		}
+		if !isEncryptedFile(buf) {
+			if f.explicit {
+				return fmt.Errorf("error: file '%s' is not encrypted", f.path)
+			}
+			continue
+		}
+		plaintext, err := decryptFile(f.path, buf, keys)
+		if err != nil {
+			return err
+		}
+		found++
+		if dryRun {
+			fmt.Println(f.path)
+			continue
+		}
+
+		encrypted, err := reencryptFile(f.path, buf, plaintext, keys, newKeys, recipients)
+		if err != nil {
+			return err
+		}
+		results = append(results, result{path: f.path, data: encrypted})
+	}
+
+	if found == 0 {
+		return fmt.Errorf("error: no encrypted env files were found")
+	}
+	for _, r := range results {
+		if err := fs.WriteFileAtomic(r.path, r.data, 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "enve: rekeyed %s\n", r.path)
+	}
+	return nil
+}
+
+// reencryptFile encrypts the plain content of an encrypted env file again using the same scheme:
+// for the given recipients if any, its current recipients with a new file key or a new passphrase.
+func reencryptFile(filePath string, buf, plaintext []byte, keys, newKeys *keySource, recipients []string) ([]byte, error) {
+	valuesOnly := !crypt.IsEncrypted(buf)
+	if recipients != nil {
+		if valuesOnly {
+			return nil, fmt.Errorf("error: file '%s' has encrypted values which cannot be encrypted for recipients", filePath)
+		}
+		return encryptFile(filePath, plaintext, nil, recipients, false)
+	}
+	if crypt.HasRecipients(buf) {
+		identities, err := env.DecryptionIdentity(filePath)
+		if err != nil {
+			return nil, err
+		}
+		current, err := crypt.Recipients(buf, identities)
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot read the recipients of file '%s'.\n%v", filePath, err)
+		}
+		return encryptFile(filePath, plaintext, nil, current, false)
+	}
+
+	oldKey, err := keys.passphrase(filePath, false)
+	if err != nil {
+		return nil, err
+	}
+	newKey, err := newKeys.passphrase(filePath, true)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(oldKey, newKey) {
+		return nil, fmt.Errorf("error: new passphrase of file '%s' is the same as the current one", filePath)
+	}
+	return encryptFile(filePath, plaintext, newKeys, nil, valuesOnly)
+}
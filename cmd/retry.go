@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joseluisq/enve/env"
+)
+
+// Supported backoff strategies used between command retries.
+const (
+	backoffConstant    = "constant"
+	backoffExponential = "exponential"
+)
+
+// maxRetryDelay is the delay the exponential backoff stops growing at.
+const maxRetryDelay = 30 * time.Second
+
+// retryOpts defines how a failed command gets retried.
+type retryOpts struct {
+	// Maximum number of retries after the first attempt.
+	retries int
+	// Delay before the first retry.
+	delay time.Duration
+	// Backoff strategy applied to the delay between retries.
+	backoff string
+	// Exit codes allowed to trigger a retry, any non-zero exit code if empty.
+	exitCodes []int
+}
+
+// parseExitCodes parses a list of exit codes ignoring empty values.
+func parseExitCodes(values []string) ([]int, error) {
+	var codes []int
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		code, err := strconv.Atoi(v)
+		if err != nil || code < 0 {
+			return nil, fmt.Errorf("error: invalid exit code '%s' for flag '--retry-on-exit'", v)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// exitCodeOf returns the exit code of a failed command or false if it did not run to completion.
+func exitCodeOf(err error) (int, bool) {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code, true
+	}
+	var cmdErr *exec.ExitError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode(), true
+	}
+	return 0, false
+}
+
+// shouldRetry checks if a command error is retryable.
+func (o retryOpts) shouldRetry(err error) bool {
+	code, ok := exitCodeOf(err)
+	if !ok {
+		return false
+	}
+	return len(o.exitCodes) == 0 || slices.Contains(o.exitCodes, code)
+}
+
+// nextDelay returns the delay before the next retry according to the backoff strategy.
+func (o retryOpts) nextDelay(delay time.Duration) time.Duration {
+	if o.backoff != backoffExponential || delay >= maxRetryDelay {
+		return delay
+	}
+	return min(delay*2, maxRetryDelay)
+}
+
+// execCmdRetry executes a command retrying it on failure according to the given options.
+// The current attempt number is exported to the command as `ENVE_ATTEMPT`.
+func execCmdRetry(tailArgs []string, chdirPath string, newEnv bool, envVars []string, timeout time.Duration, killAfter time.Duration, opts retryOpts) error {
+	delay := opts.delay
+	for attempt := 1; ; attempt++ {
+		n := strconv.Itoa(attempt)
+		vars := envVars
+		if newEnv {
+			vars = env.Slice(envVars).Merge(env.Map{"ENVE_ATTEMPT": n}, true)
+		} else if err := os.Setenv("ENVE_ATTEMPT", n); err != nil {
+			return err
+		}
+
+		err := execCmdTimeout(tailArgs, chdirPath, newEnv, vars, timeout, killAfter)
+		if err == nil || attempt > opts.retries || !opts.shouldRetry(err) {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "enve: attempt %d of %d failed (%v), retrying in %s\n", attempt, opts.retries+1, err, delay)
+		time.Sleep(delay)
+		delay = opts.nextDelay(delay)
+	}
+}
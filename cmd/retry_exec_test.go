@@ -0,0 +1,101 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_execCmdRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		script           string
+		newEnv           bool
+		opts             retryOpts
+		expectedErr      error
+		expectedAttempts string
+	}{
+		{
+			name:             "should not retry a successful command",
+			script:           "true",
+			opts:             retryOpts{retries: 3},
+			expectedAttempts: "1",
+		},
+		{
+			name:             "should retry a failed command until it succeeds",
+			script:           `test "$ENVE_ATTEMPT" -ge 3`,
+			opts:             retryOpts{retries: 3, delay: time.Millisecond, backoff: backoffExponential},
+			expectedAttempts: "123",
+		},
+		{
+			name:             "should export the attempt number into a new environment",
+			script:           `test "$ENVE_ATTEMPT" -ge 2`,
+			newEnv:           true,
+			opts:             retryOpts{retries: 1, delay: time.Millisecond},
+			expectedAttempts: "12",
+		},
+		{
+			name:             "should return the last error when retries are exhausted",
+			script:           "exit 2",
+			opts:             retryOpts{retries: 2, delay: time.Millisecond},
+			expectedErr:      errors.New("exit status 2"),
+			expectedAttempts: "123",
+		},
+		{
+			name:             "should retry only on the given exit codes",
+			script:           `test "$ENVE_ATTEMPT" -ge 2 && exit 3; exit 2`,
+			opts:             retryOpts{retries: 5, delay: time.Millisecond, exitCodes: []int{2}},
+			expectedErr:      errors.New("exit status 3"),
+			expectedAttempts: "12",
+		},
+		{
+			name:             "should not retry when the exit code is not allowed",
+			script:           "exit 1",
+			opts:             retryOpts{retries: 5, delay: time.Millisecond, exitCodes: []int{2, 3}},
+			expectedErr:      errors.New("exit status 1"),
+			expectedAttempts: "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attemptsFile := filepath.Join(t.TempDir(), "attempts")
+			t.Setenv("ENVE_ATTEMPTS_FILE", attemptsFile)
+			script := `printf "$ENVE_ATTEMPT" >> "$ENVE_ATTEMPTS_FILE"; ` + tt.script
+
+			var envVars []string
+			if tt.newEnv {
+				envVars = []string{"ENVE_ATTEMPTS_FILE=" + attemptsFile}
+			}
+
+			err := execCmdRetry([]string{"sh", "-c", script}, "", tt.newEnv, envVars, 0, 0, tt.opts)
+			if tt.expectedErr != nil {
+				assert.Error(t, err, "expected an error but got none")
+				assert.Equal(t, tt.expectedErr.Error(), err.Error(), "error message should match")
+			} else {
+				assert.NoError(t, err, "did not expect an error but got one")
+			}
+
+			attempts, err := os.ReadFile(attemptsFile)
+			assert.NoError(t, err, "should read the attempts file")
+			assert.Equal(t, tt.expectedAttempts, string(attempts), "attempts should match")
+		})
+	}
+}
+
+func Test_execCmdRetry_NotFound(t *testing.T) {
+	t.Run("should not retry when the executable is not found", func(t *testing.T) {
+		start := time.Now()
+		err := execCmdRetry([]string{"nonexistentcommand"}, "", false, nil, 0, 0, retryOpts{retries: 3, delay: time.Second})
+		assert.Error(t, err, "expected an error but got none")
+		assert.Contains(t, err.Error(), "error: executable 'nonexistentcommand' was not found.", "error message should match")
+		assert.Less(t, time.Since(start), time.Second, "should not wait for retries")
+	})
+}
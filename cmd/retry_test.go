@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseExitCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		expected    []int
+		expectedErr error
+	}{
+		{
+			name:   "should ignore empty values",
+			values: []string{""},
+		},
+		{
+			name:     "should parse valid exit codes",
+			values:   []string{"1", "2", "124"},
+			expected: []int{1, 2, 124},
+		},
+		{
+			name:        "should return an error for an invalid exit code",
+			values:      []string{"1", "one"},
+			expectedErr: errors.New("error: invalid exit code 'one' for flag '--retry-on-exit'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseExitCodes(tt.values)
+			if tt.expectedErr != nil {
+				assert.Error(t, err, "expected an error but got none")
+				assert.Equal(t, tt.expectedErr.Error(), err.Error(), "error message should match")
+			} else {
+				assert.NoError(t, err, "did not expect an error but got one")
+				assert.Equal(t, tt.expected, actual, "exit codes should match")
+			}
+		})
+	}
+}
+
+func Test_retryOpts_nextDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  string
+		delay    time.Duration
+		expected time.Duration
+	}{
+		{
+			name:     "should keep a constant delay",
+			backoff:  backoffConstant,
+			delay:    time.Minute,
+			expected: time.Minute,
+		},
+		{
+			name:     "should double an exponential delay",
+			backoff:  backoffExponential,
+			delay:    time.Second,
+			expected: 2 * time.Second,
+		},
+		{
+			name:     "should cap an exponential delay",
+			backoff:  backoffExponential,
+			delay:    20 * time.Second,
+			expected: maxRetryDelay,
+		},
+		{
+			name:     "should keep an initial delay over the cap",
+			backoff:  backoffExponential,
+			delay:    time.Minute,
+			expected: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := retryOpts{backoff: tt.backoff}
+			assert.Equal(t, tt.expected, opts.nextDelay(tt.delay), "delay should match")
+		})
+	}
+}
+
+func Test_retryOpts_shouldRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		exitCodes []int
+		expected  bool
+	}{
+		{
+			name:     "should retry any exit code by default",
+			err:      &ExitError{Code: 1},
+			expected: true,
+		},
+		{
+			name:      "should retry an allowed exit code",
+			err:       &ExitError{Code: 2},
+			exitCodes: []int{1, 2},
+			expected:  true,
+		},
+		{
+			name:      "should not retry an exit code which is not allowed",
+			err:       &ExitError{Code: 3},
+			exitCodes: []int{1, 2},
+		},
+		{
+			name: "should not retry a command which did not run",
+			err:  errors.New("error: executable 'nonexistentcommand' was not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := retryOpts{exitCodes: tt.exitCodes}
+			assert.Equal(t, tt.expected, opts.shouldRetry(tt.err), "retry decision should match")
+		})
+	}
+}
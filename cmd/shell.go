@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/env"
+)
+
+// bashRC is the bash startup file prefixing the prompt once the user one was sourced,
+// since ~/.bashrc usually overrides the PS1 variable passed through the environment.
+const bashRC = `if [ -f ~/.bashrc ]; then . ~/.bashrc; fi
+PS1="(enve) $PS1"
+`
+
+// shellEnv builds the environment of an interactive shell.
+// Isolated environments keep the default POSIX variables so the shell remains usable.
+// The bash prompt is not part of the environment but prefixed by its startup file, see bashRC.
+func shellEnv(environ *environ, shellPath string, prompt bool) (env.Slice, error) {
+	vars := environ.vars
+	if environ.isolated() {
+		kept, err := environ.baseEnv.Match(defaultKeepVars...)
+		if err != nil {
+			return nil, err
+		}
+		vars = vars.Merge(kept.Map(), false)
+	}
+
+	var files []string
+	for _, f := range environ.files() {
+		if abs, err := filepath.Abs(f); err == nil {
+			f = abs
+		}
+		files = append(files, f)
+	}
+
+	extra := env.Map{
+		"ENVE_ACTIVE": "1",
+		"ENVE_FILES":  strings.Join(files, string(filepath.ListSeparator)),
+	}
+	if prompt && !isBash(shellPath) {
+		if ps1, ok := vars.Map()["PS1"]; ok {
+			extra["PS1"] = "(enve) " + ps1
+		}
+	}
+	return vars.Merge(extra, true), nil
+}
+
+// isBash checks if the given shell path refers to bash.
+func isBash(shellPath string) bool {
+	return strings.TrimSuffix(filepath.Base(shellPath), ".exe") == "bash"
+}
+
+// writeBashRC writes the bash startup file into a temporary file returning its path.
+func writeBashRC() (string, error) {
+	f, err := os.CreateTemp("", "enve-bashrc-*")
+	if err != nil {
+		return "", fmt.Errorf("error: cannot create the bash startup file.\n%v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(bashRC); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("error: cannot write the bash startup file.\n%v", err)
+	}
+	return f.Name(), nil
+}
+
+// shellHandler launches an interactive shell using the environment loaded by the application flags.
+func shellHandler(ctx *app.CmdContext) error {
+	// shell option
+	shellF, err := ctx.Flags.String("shell")
+	if err != nil {
+		return err
+	}
+
+	// no-prompt option
+	noPromptF, err := ctx.Flags.Bool("no-prompt")
+	if err != nil {
+		return err
+	}
+	noPrompt, err := noPromptF.Value()
+	if err != nil {
+		return err
+	}
+
+	environ, err := loadEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	shell := shellF.Value()
+	if shell == "" {
+		shell = environ.baseEnv.Map()["SHELL"]
+	}
+	if shell == "" {
+		shell = defaultShell
+	}
+	shellPath, err := exec.LookPath(shell)
+	if err != nil {
+		return fmt.Errorf("error: shell '%s' was not found.\n%v", shell, err)
+	}
+
+	vars, err := shellEnv(environ, shellPath, !noPrompt)
+	if err != nil {
+		return err
+	}
+
+	args := ctx.TailArgs
+	if !noPrompt && isBash(shellPath) {
+		rcPath, err := writeBashRC()
+		if err != nil {
+			return err
+		}
+		defer os.Remove(rcPath)
+		// NOTE: long bash options must precede the single-character ones
+		args = append([]string{"--rcfile", rcPath}, args...)
+	}
+
+	cmd := exec.Command(shellPath, args...)
+	cmd.Env = vars
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// NOTE: interrupts are handled by the interactive shell
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	fmt.Fprintf(os.Stderr, "enve: entering %s, type 'exit' to leave\n", filepath.Base(shellPath))
+	err = cmd.Run()
+	fmt.Fprintln(os.Stderr, "enve: leaving shell")
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.ExitCode(), Err: err}
+	}
+	return err
+}
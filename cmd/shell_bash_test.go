@@ -0,0 +1,42 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_bashRC(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not available")
+	}
+
+	home := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), []byte("PS1='custom$ '\n"), 0644))
+
+	rcPath, err := writeBashRC()
+	assert.NoError(t, err)
+	defer os.Remove(rcPath)
+
+	t.Run("should prefix the prompt set by the user startup file", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(bash, "--rcfile", rcPath, "-i")
+		cmd.Env = []string{"HOME=" + home, "PS1=$ ", "PATH=" + os.Getenv("PATH")}
+		cmd.Stdin = strings.NewReader("echo \"prompt:$PS1\"\nexit\n")
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		assert.NoError(t, cmd.Run())
+		assert.Contains(t, stdout.String(), "prompt:(enve) custom$ ")
+		// NOTE: interactive bash prints its prompt to stderr
+		assert.Contains(t, stderr.String(), "(enve) custom$ ")
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_shellEnv(t *testing.T) {
+	absPath, err := filepath.Abs("dev.env")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		environ  *environ
+		shell    string
+		prompt   bool
+		expected env.Slice
+	}{
+		{
+			name: "should set the enve variables",
+			environ: &environ{
+				vars:     env.Slice{"HOST=localhost"},
+				baseEnv:  env.Slice{"PATH=/bin"},
+				filePath: "dev.env",
+			},
+			expected: env.Slice{"HOST=localhost", "ENVE_ACTIVE=1", "ENVE_FILES=" + absPath},
+		},
+		{
+			name: "should keep the default variables in an isolated environment",
+			environ: &environ{
+				vars:    env.Slice{"HOST=localhost", "HOME=/srv"},
+				baseEnv: env.Slice{"PATH=/bin", "HOME=/root", "SECRET=xyz"},
+				newEnv:  true,
+				noFile:  true,
+			},
+			expected: env.Slice{"HOST=localhost", "HOME=/srv", "PATH=/bin", "ENVE_ACTIVE=1", "ENVE_FILES="},
+		},
+		{
+			name: "should prefix the prompt",
+			environ: &environ{
+				vars:   env.Slice{"PS1=$ "},
+				noFile: true,
+			},
+			prompt:   true,
+			expected: env.Slice{"PS1=(enve) $ ", "ENVE_ACTIVE=1", "ENVE_FILES="},
+		},
+		{
+			name: "should leave the bash prompt to its startup file",
+			environ: &environ{
+				vars:   env.Slice{"PS1=$ "},
+				noFile: true,
+			},
+			shell:    "/bin/bash",
+			prompt:   true,
+			expected: env.Slice{"PS1=$ ", "ENVE_ACTIVE=1", "ENVE_FILES="},
+		},
+		{
+			name: "should not set a prompt for other shells when not defined",
+			environ: &environ{
+				stdin: true,
+			},
+			shell:    "/usr/bin/zsh",
+			prompt:   true,
+			expected: env.Slice{"ENVE_ACTIVE=1", "ENVE_FILES="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := shellEnv(tt.environ, tt.shell, tt.prompt)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
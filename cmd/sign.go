@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/fs"
+	"github.com/joseluisq/enve/signature"
+)
+
+// readPublicKey returns the Ed25519 public key of the given PEM file.
+func readPublicKey(filePath string) (ed25519.PublicKey, error) {
+	if err := fs.FileExists(filePath); err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot read public key file '%s'.\n%v", filePath, err)
+	}
+	key, err := signature.ParsePublicKey(buf)
+	if err != nil {
+		return nil, fmt.Errorf("error: public key file '%s' is invalid.\n%v", filePath, err)
+	}
+	return key, nil
+}
+
+// signHandler writes the detached signatures of the given env files (or the one of the `--file` flag).
+func signHandler(ctx *app.CmdContext) error {
+	// key option
+	keyF, err := ctx.Flags.String("key")
+	if err != nil {
+		return err
+	}
+	if !keyF.IsProvided() || keyF.Value() == "" {
+		return fmt.Errorf("error: private key was not provided, use the '--key' flag")
+	}
+	keyPath := keyF.Value()
+	if err := fs.FileExists(keyPath); err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("error: cannot read private key file '%s'.\n%v", keyPath, err)
+	}
+	key, err := signature.ParsePrivateKey(buf)
+	if err != nil {
+		return fmt.Errorf("error: private key file '%s' is invalid.\n%v", keyPath, err)
+	}
+
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := fs.FileExists(file); err != nil {
+			return err
+		}
+		sigPath, err := signature.SignFile(file, key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "enve: signed %s (%s)\n", file, sigPath)
+	}
+	return nil
+}
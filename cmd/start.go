@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// Default Procfile runner settings.
+const (
+	procDefaultPort = 5000
+	procPortStep    = 100
+	procKillAfter   = 10 * time.Second
+)
+
+// procNameRegex validates Procfile process type names.
+var procNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// procEntry defines a Procfile process type.
+type procEntry struct {
+	name    string
+	command string
+}
+
+// parseProcfile parses Procfile entries in `name: command` format skipping blank lines and comments.
+func parseProcfile(r io.Reader) ([]procEntry, error) {
+	var procs []procEntry
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, command, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		command = strings.TrimSpace(command)
+		if !ok || !procNameRegex.MatchString(name) || command == "" {
+			return nil, fmt.Errorf("error: invalid Procfile entry at line %d", n)
+		}
+		for _, p := range procs {
+			if p.name == name {
+				return nil, fmt.Errorf("error: duplicated Procfile process '%s' at line %d", name, n)
+			}
+		}
+		procs = append(procs, procEntry{name: name, command: command})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return procs, nil
+}
+
+// procResult holds the outcome of a Procfile process.
+type procResult struct {
+	name string
+	err  error
+}
+
+// runProcs runs the Procfile processes sharing the same environment and assigning incrementing ports.
+// Once a process exits or the context is done, the remaining processes get terminated.
+func runProcs(ctx context.Context, procs []procEntry, envVars env.Slice, basePort int, out io.Writer) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	width := len("enve")
+	for _, p := range procs {
+		width = max(width, len(p.name))
+	}
+
+	var mu sync.Mutex
+	color := useColors(out)
+	system := newPrefixWriter(&mu, out, formatPrefix("enve", width, len(procs), color))
+
+	var wg sync.WaitGroup
+	results := make(chan procResult, len(procs))
+	for i, p := range procs {
+		port := basePort + i*procPortStep
+		w := newPrefixWriter(&mu, out, formatPrefix(p.name, width, i, color))
+
+		cmd := shellCmd(runCtx, p.command)
+		cmd.WaitDelay = procKillAfter
+		cmd.Env = envVars.Merge(env.Map{"PORT": strconv.Itoa(port)}, true)
+		cmd.Stdout = w
+		cmd.Stderr = w
+
+		fmt.Fprintf(system, "starting %s on port %d\n", p.name, port)
+		if err := cmd.Start(); err != nil {
+			results <- procResult{name: p.name, err: err}
+			break
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := cmd.Wait()
+			_ = w.Flush()
+			results <- procResult{name: name, err: err}
+		}(p.name)
+	}
+
+	var first procResult
+	select {
+	case first = <-results:
+	case <-ctx.Done():
+	}
+
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		fmt.Fprintln(system, "interrupted, stopping all processes")
+	} else if first.err != nil {
+		fmt.Fprintf(system, "%s exited (%v), stopping all processes\n", first.name, first.err)
+	} else {
+		fmt.Fprintf(system, "%s exited, stopping all processes\n", first.name)
+	}
+
+	cancel()
+	wg.Wait()
+
+	if !interrupted && first.err != nil {
+		return fmt.Errorf("error: process '%s' exited.\n%v", first.name, first.err)
+	}
+	return nil
+}
+
+// startHandler runs every Procfile process using the environment loaded by the application flags.
+func startHandler(ctx *app.CmdContext) error {
+	// procfile option
+	procfileF, err := ctx.Flags.String("procfile")
+	if err != nil {
+		return err
+	}
+	procfilePath := procfileF.Value()
+
+	// port option
+	portF, err := ctx.Flags.Int("port")
+	if err != nil {
+		return err
+	}
+	port, err := portF.Value()
+	if err != nil {
+		return err
+	}
+
+	environ, err := loadEnviron(ctx.AppContext.Flags())
+	if err != nil {
+		return err
+	}
+
+	if err := fs.FileExists(procfilePath); err != nil {
+		return err
+	}
+	f, err := os.Open(procfilePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	procs, err := parseProcfile(f)
+	if err != nil {
+		return err
+	}
+
+	// Run only the given process types if any
+	if len(ctx.TailArgs) > 0 {
+		var selected []procEntry
+		for _, name := range ctx.TailArgs {
+			i := slices.IndexFunc(procs, func(p procEntry) bool { return p.name == name })
+			if i < 0 {
+				return fmt.Errorf("error: process '%s' was not found in '%s'", name, procfilePath)
+			}
+			selected = append(selected, procs[i])
+		}
+		procs = selected
+	}
+	if len(procs) == 0 {
+		return fmt.Errorf("error: no processes were found in '%s'", procfilePath)
+	}
+
+	if !portF.IsProvided() {
+		port = procDefaultPort
+		if v, ok := environ.vars.Map()["PORT"]; ok {
+			if port, err = strconv.Atoi(v); err != nil {
+				return fmt.Errorf("error: invalid PORT value '%s'", v)
+			}
+		}
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runProcs(sigCtx, procs, environ.vars, port, os.Stdout)
+}
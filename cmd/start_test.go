@@ -0,0 +1,125 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func Test_parseProcfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []procEntry
+		expectedErr error
+	}{
+		{
+			name:  "should return no entries for an empty Procfile",
+			input: "",
+		},
+		{
+			name:  "should parse entries skipping blank lines and comments",
+			input: "# processes\nweb: ./server --port $PORT\n\nworker:   ./worker  \n",
+			expected: []procEntry{
+				{name: "web", command: "./server --port $PORT"},
+				{name: "worker", command: "./worker"},
+			},
+		},
+		{
+			name:     "should keep colons inside commands",
+			input:    "clock: echo 12:00",
+			expected: []procEntry{{name: "clock", command: "echo 12:00"}},
+		},
+		{
+			name:        "should return an error for an entry without a command",
+			input:       "web: ./server\nworker:",
+			expectedErr: errors.New("error: invalid Procfile entry at line 2"),
+		},
+		{
+			name:        "should return an error for an invalid process name",
+			input:       "web server: ./server",
+			expectedErr: errors.New("error: invalid Procfile entry at line 1"),
+		},
+		{
+			name:        "should return an error for a duplicated process",
+			input:       "web: ./server\nweb: ./other",
+			expectedErr: errors.New("error: duplicated Procfile process 'web' at line 2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseProcfile(strings.NewReader(tt.input))
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_runProcs(t *testing.T) {
+	envVars := env.Slice{"PATH=/usr/bin:/bin", "GREETING=hello"}
+
+	t.Run("should run processes with incrementing ports and stop them when one exits", func(t *testing.T) {
+		procs := []procEntry{
+			{name: "web", command: `echo "web $GREETING $PORT"; exec sleep 10`},
+			{name: "worker", command: `echo "worker $PORT"; sleep 0.2`},
+		}
+
+		var out bytes.Buffer
+		start := time.Now()
+		err := runProcs(context.Background(), procs, envVars, 6000, &out)
+
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second, "remaining processes should be stopped")
+		assert.Contains(t, out.String(), "enve   | starting web on port 6000\n")
+		assert.Contains(t, out.String(), "enve   | starting worker on port 6100\n")
+		assert.Contains(t, out.String(), "web    | web hello 6000\n")
+		assert.Contains(t, out.String(), "worker | worker 6100\n")
+		assert.Contains(t, out.String(), "enve   | worker exited, stopping all processes\n")
+	})
+
+	t.Run("should return an error when a process fails", func(t *testing.T) {
+		procs := []procEntry{
+			{name: "web", command: "exec sleep 10"},
+			{name: "job", command: "exit 3"},
+		}
+
+		var out bytes.Buffer
+		err := runProcs(context.Background(), procs, envVars, 5000, &out)
+
+		assert.EqualError(t, err, "error: process 'job' exited.\nexit status 3")
+		assert.Contains(t, out.String(), "enve | job exited (exit status 3), stopping all processes\n")
+	})
+
+	t.Run("should stop all processes when the context is done", func(t *testing.T) {
+		procs := []procEntry{
+			{name: "web", command: "exec sleep 10"},
+			{name: "worker", command: "exec sleep 10"},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		var out bytes.Buffer
+		start := time.Now()
+		err := runProcs(ctx, procs, envVars, 5000, &out)
+
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second, "processes should be stopped")
+		assert.Contains(t, out.String(), "interrupted, stopping all processes")
+	})
+}
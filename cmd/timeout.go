@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// timeoutExitCode is the exit code used when a command times out like GNU `timeout` does.
+const timeoutExitCode = 124
+
+// execCmd executes a command along with its env variables
+func execCmd(tailArgs []string, chdirPath string, newEnv bool, envVars []string) error {
+	return execCmdTimeout(tailArgs, chdirPath, newEnv, envVars, 0, 0)
+}
+
+// stdio holds the standard streams a command is attached to.
+type stdio struct {
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+}
+
+// processStdio returns the standard streams of the current process.
+func processStdio() stdio {
+	return stdio{in: os.Stdin, out: os.Stdout, err: os.Stderr}
+}
+
+// execCmdTimeout executes a command which gets terminated once the timeout expires.
+// A zero timeout means that the command can run without time limit.
+func execCmdTimeout(tailArgs []string, chdirPath string, newEnv bool, envVars []string, timeout time.Duration, killAfter time.Duration) error {
+	return execCmdTimeoutIO(tailArgs, chdirPath, newEnv, envVars, timeout, killAfter, processStdio())
+}
+
+// execCmdTimeoutIO executes a command attached to the given streams which gets terminated once the timeout expires.
+func execCmdTimeoutIO(tailArgs []string, chdirPath string, newEnv bool, envVars []string, timeout time.Duration, killAfter time.Duration, std stdio) error {
+	if timeout <= 0 {
+		return execCmdContext(context.Background(), tailArgs, chdirPath, newEnv, envVars, killAfter, std)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := execCmdContext(ctx, tailArgs, chdirPath, newEnv, envVars, killAfter, std)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &ExitError{
+			Code: timeoutExitCode,
+			Err:  fmt.Errorf("error: command timed out after %s", timeout),
+		}
+	}
+	return err
+}
+
+// execCmdContext executes a command along with its env variables attached to the given streams.
+// When the context is done the command gets terminated as described by newCmd,
+// then killed if it is still running after the `killAfter` grace period.
+func execCmdContext(ctx context.Context, tailArgs []string, chdirPath string, newEnv bool, envVars []string, killAfter time.Duration, std stdio) error {
+	cmd, err := newCmd(ctx, tailArgs)
+	if err != nil {
+		return err
+	}
+	cmd.WaitDelay = killAfter
+	cmd.Dir = chdirPath
+	if newEnv {
+		// NOTE: a nil env would make the command inherit the current environment
+		cmd.Env = append([]string{}, envVars...)
+	}
+	cmd.Stdin = std.in
+	cmd.Stdout = std.out
+	cmd.Stderr = std.err
+	return cmd.Run()
+}
+
+// parseDuration parses a duration flag value which also accepts plain seconds like `10` or `0.5`.
+func parseDuration(name string, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(value, 64)
+		if ferr != nil {
+			return 0, fmt.Errorf("error: invalid duration '%s' for flag '--%s'", value, name)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("error: invalid duration '%s' for flag '--%s'", value, name)
+	}
+	return d, nil
+}
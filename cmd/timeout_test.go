@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Duration
+		expectedErr error
+	}{
+		{
+			name:     "should parse a Go duration",
+			value:    "1m30s",
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "should parse plain seconds",
+			value:    "10",
+			expected: 10 * time.Second,
+		},
+		{
+			name:     "should parse fractional seconds",
+			value:    "0.5",
+			expected: 500 * time.Millisecond,
+		},
+		{
+			name:        "should return an error for an invalid duration",
+			value:       "abc",
+			expectedErr: errors.New("error: invalid duration 'abc' for flag '--timeout'"),
+		},
+		{
+			name:        "should return an error for a negative duration",
+			value:       "-5s",
+			expectedErr: errors.New("error: invalid duration '-5s' for flag '--timeout'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseDuration("timeout", tt.value)
+			if tt.expectedErr != nil {
+				assert.Error(t, err, "expected an error but got none")
+				assert.Equal(t, tt.expectedErr.Error(), err.Error(), "error message should match")
+			} else {
+				assert.NoError(t, err, "did not expect an error but got one")
+				assert.Equal(t, tt.expected, actual, "duration should match")
+			}
+		})
+	}
+}
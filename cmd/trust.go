@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/cline/app"
+
+	"github.com/joseluisq/enve/fs"
+	"github.com/joseluisq/enve/trust"
+)
+
+// trustError reports an env file which cannot be loaded since it is not trusted.
+type trustError struct {
+	error
+}
+
+// checkTrust returns an error if the given env file content is not trusted or was modified since it was trusted.
+func checkTrust(filePath string, buf []byte) error {
+	store, err := trust.Default()
+	if err != nil {
+		return &trustError{err}
+	}
+	status, err := store.CheckContent(filePath, buf)
+	if err != nil {
+		return &trustError{err}
+	}
+	switch status {
+	case trust.Untrusted:
+		return &trustError{fmt.Errorf("error: env file '%s' is not trusted, run 'enve allow %s' to trust it", filePath, filePath)}
+	case trust.Modified:
+		return &trustError{fmt.Errorf("error: env file '%s' was modified since it was trusted, review it and run 'enve allow %s' to trust it again", filePath, filePath)}
+	}
+	return nil
+}
+
+// allowHandler trusts the given env files with their current content.
+func allowHandler(ctx *app.CmdContext) error {
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+	store, err := trust.Default()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := fs.FileExists(f); err != nil {
+			return err
+		}
+		absPath, err := store.Allow(f)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "enve: allowed %s\n", absPath)
+	}
+	return nil
+}
+
+// denyHandler removes the trust of the given env files.
+func denyHandler(ctx *app.CmdContext) error {
+	files, err := commandFiles(ctx)
+	if err != nil {
+		return err
+	}
+	store, err := trust.Default()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		absPath, err := store.Deny(f)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "enve: denied %s\n", absPath)
+	}
+	return nil
+}
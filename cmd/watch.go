@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joseluisq/enve/env"
+)
+
+// Default watch mode settings.
+const (
+	watchInterval  = 250 * time.Millisecond
+	watchDebounce  = 500 * time.Millisecond
+	watchKillAfter = 10 * time.Second
+)
+
+// watcher restarts a command every time one of its watched paths changes.
+type watcher struct {
+	// File or directory paths to monitor.
+	paths []string
+	// Polling interval used to detect changes.
+	interval time.Duration
+	// Quiet period to wait after the last change before restarting.
+	debounce time.Duration
+	// Grace period before killing a command that did not terminate on restart.
+	killAfter time.Duration
+	// Expand the variable references of the command arguments using every reloaded environment.
+	expandArgs bool
+}
+
+// stamp computes a fingerprint of a path which changes when its content does.
+// Directories are walked recursively using their file sizes and modification times.
+func stamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "missing"
+	}
+
+	h := sha256.New()
+	if info.IsDir() {
+		_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if fi, err := d.Info(); err == nil {
+				fmt.Fprintf(h, "%s|%d|%d\n", p, fi.Size(), fi.ModTime().UnixNano())
+			}
+			return nil
+		})
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return "unreadable"
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return "unreadable"
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// stamps computes the fingerprints of all watched paths.
+func (w *watcher) stamps() map[string]string {
+	s := make(map[string]string, len(w.paths))
+	for _, p := range w.paths {
+		s[p] = stamp(p)
+	}
+	return s
+}
+
+// changed reports whether two sets of fingerprints differ.
+func changed(a, b map[string]string) bool {
+	for k, v := range a {
+		if b[k] != v {
+			return true
+		}
+	}
+	return len(a) != len(b)
+}
+
+// process represents a command running in watch mode.
+type process struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// stop terminates the process and waits for it to exit.
+func (p *process) stop() {
+	p.cancel()
+	<-p.done
+}
+
+// start executes the command in background.
+func (w *watcher) start(tailArgs []string, chdirPath string, envVars env.Slice) *process {
+	if w.expandArgs {
+		tailArgs = envVars.Expand(tailArgs)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &process{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		p.err = execCmdContext(ctx, tailArgs, chdirPath, true, envVars, w.killAfter, processStdio())
+		close(p.done)
+	}()
+	return p
+}
+
+// run executes the command and restarts it with a reloaded environment every time the watched paths change.
+// An environment failing to reload is reported while the previous command keeps running.
+// It returns once the context is done.
+func (w *watcher) run(ctx context.Context, tailArgs []string, chdirPath string, load func() (env.Slice, error)) error {
+	envVars, err := load()
+	if err != nil {
+		return err
+	}
+
+	last := w.stamps()
+	proc := w.start(tailArgs, chdirPath, envVars)
+	exited := proc.done
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var pending bool
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			proc.stop()
+			return nil
+		case <-exited:
+			if proc.err != nil {
+				fmt.Fprintf(os.Stderr, "enve: command exited (%v), waiting for changes...\n", proc.err)
+			} else {
+				fmt.Fprintln(os.Stderr, "enve: command exited, waiting for changes...")
+			}
+			exited = nil
+		case <-ticker.C:
+			if current := w.stamps(); changed(last, current) {
+				last = current
+				pending = true
+				changedAt = time.Now()
+				continue
+			}
+			if !pending || time.Since(changedAt) < w.debounce {
+				continue
+			}
+			pending = false
+
+			vars, err := load()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "enve: cannot reload environment, keeping the previous one.\n%v\n", err)
+				continue
+			}
+
+			fmt.Fprintln(os.Stderr, "enve: changes detected, restarting command...")
+			proc.stop()
+			proc = w.start(tailArgs, chdirPath, vars)
+			exited = proc.done
+		}
+	}
+}
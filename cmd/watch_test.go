@@ -0,0 +1,121 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/trust"
+)
+
+func Test_stamp(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.env")
+
+	t.Run("should return a missing stamp for non-existent paths", func(t *testing.T) {
+		assert.Equal(t, "missing", stamp(file))
+	})
+
+	t.Run("should change when a file content changes", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(file, []byte("KEY=one"), 0644))
+		first := stamp(file)
+		assert.NoError(t, os.WriteFile(file, []byte("KEY=two"), 0644))
+		assert.NotEqual(t, first, stamp(file))
+	})
+
+	t.Run("should match the content hash of files used by the shell hook", func(t *testing.T) {
+		assert.Equal(t, trust.HashContent([]byte("KEY=two")), stamp(file))
+	})
+
+	t.Run("should change when a directory content changes", func(t *testing.T) {
+		first := stamp(dir)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.env"), []byte("KEY=one"), 0644))
+		assert.NotEqual(t, first, stamp(dir))
+	})
+}
+
+func Test_watcher_run(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "test.env")
+	outFile := filepath.Join(dir, "out.txt")
+
+	assert.NoError(t, os.WriteFile(envFile, []byte("VALUE=one"), 0644))
+
+	load := func() (env.Slice, error) {
+		envf, err := env.FromPath(envFile)
+		if err != nil {
+			return nil, err
+		}
+		defer envf.Close()
+		vmap, err := envf.Parse()
+		if err != nil {
+			return nil, err
+		}
+		return env.Slice{"OUT_FILE=" + outFile}.Merge(vmap, false), nil
+	}
+
+	readOut := func() string {
+		data, _ := os.ReadFile(outFile)
+		return strings.TrimSpace(string(data))
+	}
+
+	w := &watcher{
+		paths:      []string{envFile},
+		interval:   10 * time.Millisecond,
+		debounce:   50 * time.Millisecond,
+		killAfter:  time.Second,
+		expandArgs: true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		tailArgs := []string{"sh", "-c", `echo "$VALUE-$1" >> "$OUT_FILE"; exec sleep 10`, "sh", "${VALUE}"}
+		errc <- w.run(ctx, tailArgs, "", load)
+	}()
+
+	t.Run("should start the command with the initial environment", func(t *testing.T) {
+		assert.Eventually(t, func() bool { return readOut() == "one-one" }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should restart the command expanding its arguments when the env file changes", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(envFile, []byte("VALUE=two"), 0644))
+		assert.Eventually(t, func() bool { return readOut() == "one-one\ntwo-two" }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("should keep the previous command running on invalid changes", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(envFile, []byte("INVALID-INPUT"), 0644))
+		assert.Never(t, func() bool { return readOut() != "one-one\ntwo-two" }, 300*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("should stop the command when the context is done", func(t *testing.T) {
+		cancel()
+		select {
+		case err := <-errc:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "watcher did not stop in time")
+		}
+	})
+}
+
+func Test_watcher_run_LoadError(t *testing.T) {
+	t.Run("should return an error when the initial environment cannot be loaded", func(t *testing.T) {
+		w := &watcher{interval: 10 * time.Millisecond, debounce: 10 * time.Millisecond}
+		load := func() (env.Slice, error) {
+			return nil, errors.New("error: cannot access file 'missing.env'.")
+		}
+		err := w.run(context.Background(), []string{"true"}, "", load)
+		assert.EqualError(t, err, "error: cannot access file 'missing.env'.")
+	})
+}
@@ -0,0 +1,167 @@
+// Package crypt provides an encrypted env file format using AES-256-GCM
+// along with a key derived from a passphrase via scrypt,
+// as well as age files encrypted for X25519 recipients.
+// It also decrypts the dotenv-vault (.env.vault) format.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// blockType is the PEM type of encrypted env files.
+	blockType = "ENVE ENCRYPTED FILE"
+	// Header is the first line of encrypted env files.
+	Header = "-----BEGIN " + blockType + "-----"
+
+	cipherName = "AES-256-GCM"
+	kdfName    = "scrypt"
+	keyLen     = 32
+	saltLen    = 16
+	// NOTE: upper bounds of the scrypt parameters accepted when decrypting
+	maxCost        = 1 << 20
+	maxBlockSize   = 32
+	maxParallelism = 16
+	// NOTE: the parameters are bounded together too since scrypt uses 128*N*r bytes
+	// of memory and runs N*r*p block mixes, which is 32 MiB and 2^18 for new files
+	maxMemory = 256 << 20
+	maxWork   = 1 << 22
+)
+
+// scrypt parameters used to derive the keys of new files.
+const (
+	costN = 1 << 15
+	costR = 8
+	costP = 1
+)
+
+// ErrDecrypt is returned when a file cannot be authenticated with the given passphrase.
+var ErrDecrypt = errors.New("the passphrase is wrong or the file was modified")
+
+// IsEncrypted reports whether the given content is an encrypted env file,
+// either using a passphrase or for recipients.
+func IsEncrypted(buf []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(buf, " \t\r\n"), []byte(Header)) || HasRecipients(buf)
+}
+
+// Encrypt returns the given content encrypted with a key derived from the passphrase.
+func Encrypt(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("the passphrase is empty")
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"Cipher":     cipherName,
+		"Kdf":        kdfName,
+		"Kdf-Params": params,
+		"Salt":       base64.StdEncoding.EncodeToString(salt),
+	}
+	key, err := scrypt.Key(passphrase, salt, costN, costR, costP, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	ciphertext := aead.Seal(nonce, nonce, plaintext, additionalData(headers))
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Headers: headers, Bytes: ciphertext}), nil
+}
+
+// Decrypt returns the content of an encrypted env file.
+func Decrypt(buf, passphrase []byte) ([]byte, error) {
+	if HasRecipients(buf) {
+		return nil, errors.New("the file is encrypted for recipients instead of a passphrase")
+	}
+	block, _ := pem.Decode(bytes.TrimLeft(buf, " \t\r\n"))
+	if block == nil || block.Type != blockType {
+		return nil, errors.New("the content is not an encrypted env file")
+	}
+	if c := block.Headers["Cipher"]; c != cipherName {
+		return nil, fmt.Errorf("cipher '%s' is not supported", c)
+	}
+	if k := block.Headers["Kdf"]; k != kdfName {
+		return nil, fmt.Errorf("key derivation function '%s' is not supported", k)
+	}
+	n, r, p, err := parseParams(block.Headers["Kdf-Params"])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("salt is invalid")
+	}
+
+	key, err := scrypt.Key(passphrase, salt, n, r, p, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	return open(block, key)
+}
+
+// params are the scrypt parameters used to derive the keys of new files.
+var params = fmt.Sprintf("N=%d,r=%d,p=%d", costN, costR, costP)
+
+// parseParams returns the scrypt parameters of a file refusing the ones too expensive to compute.
+func parseParams(s string) (n, r, p int, err error) {
+	if _, err := fmt.Sscanf(s, "N=%d,r=%d,p=%d", &n, &r, &p); err != nil {
+		return 0, 0, 0, fmt.Errorf("key derivation parameters are invalid.\n%v", err)
+	}
+	if n <= 1 || n > maxCost || n&(n-1) != 0 || r <= 0 || r > maxBlockSize || p <= 0 || p > maxParallelism ||
+		128*n*r > maxMemory || n*r*p > maxWork {
+		return 0, 0, 0, fmt.Errorf("key derivation parameters '%s' are not supported", s)
+	}
+	return n, r, p, nil
+}
+
+// newAEAD returns the AES-256-GCM cipher of the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the headers to the ciphertext so they cannot be modified.
+func additionalData(headers map[string]string) []byte {
+	fields := []string{blockType}
+	for _, k := range []string{"Cipher", "Kdf", "Kdf-Params", "Salt"} {
+		fields = append(fields, k+": "+headers[k])
+	}
+	return []byte(strings.Join(fields, "\n"))
+}
+
+// open decrypts the content of a file using its key.
+func open(block *pem.Block, key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(block.Bytes) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := block.Bytes[:aead.NonceSize()], block.Bytes[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(block.Headers))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,128 @@
+package crypt
+
+import (
+	"bytes"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypt(t *testing.T) {
+	plaintext := []byte("# Secrets\nDB_PASSWORD=s3cr3t\n")
+
+	t.Run("should encrypt and decrypt a file", func(t *testing.T) {
+		buf, err := Encrypt(plaintext, []byte("passphrase"))
+		assert.NoError(t, err)
+		assert.True(t, IsEncrypted(buf))
+		assert.NotContains(t, string(buf), "s3cr3t")
+
+		decrypted, err := Decrypt(buf, []byte("passphrase"))
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("should use a random salt and nonce", func(t *testing.T) {
+		a, err := Encrypt(plaintext, []byte("passphrase"))
+		assert.NoError(t, err)
+		b, err := Encrypt(plaintext, []byte("passphrase"))
+		assert.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("should return an error for an empty passphrase", func(t *testing.T) {
+		_, err := Encrypt(plaintext, nil)
+		assert.EqualError(t, err, "the passphrase is empty")
+	})
+}
+
+func TestDecrypt(t *testing.T) {
+	buf, err := Encrypt([]byte("A=1\n"), []byte("passphrase"))
+	assert.NoError(t, err)
+
+	tamper := func(change func(b *pem.Block)) []byte {
+		block, _ := pem.Decode(buf)
+		change(block)
+		return pem.EncodeToMemory(block)
+	}
+
+	tests := []struct {
+		name        string
+		input       []byte
+		passphrase  string
+		expectedErr string
+	}{
+		{
+			name:        "should return an error for a wrong passphrase",
+			input:       buf,
+			passphrase:  "wrong",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for a modified ciphertext",
+			input:       tamper(func(b *pem.Block) { b.Bytes[len(b.Bytes)-1] ^= 1 }),
+			passphrase:  "passphrase",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for a modified salt",
+			input:       tamper(func(b *pem.Block) { b.Headers["Salt"] = "AAAAAAAAAAAAAAAAAAAAAA==" }),
+			passphrase:  "passphrase",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for unsupported key derivation parameters",
+			input:       tamper(func(b *pem.Block) { b.Headers["Kdf-Params"] = "N=1073741824,r=8,p=1" }),
+			passphrase:  "passphrase",
+			expectedErr: "key derivation parameters 'N=1073741824,r=8,p=1' are not supported",
+		},
+		{
+			name:        "should return an error for a too large block size",
+			input:       tamper(func(b *pem.Block) { b.Headers["Kdf-Params"] = "N=16384,r=1024,p=1" }),
+			passphrase:  "passphrase",
+			expectedErr: "key derivation parameters 'N=16384,r=1024,p=1' are not supported",
+		},
+		{
+			name:        "should return an error for a too large parallelism",
+			input:       tamper(func(b *pem.Block) { b.Headers["Kdf-Params"] = "N=16384,r=8,p=64" }),
+			passphrase:  "passphrase",
+			expectedErr: "key derivation parameters 'N=16384,r=8,p=64' are not supported",
+		},
+		{
+			name:        "should return an error for a too large memory cost",
+			input:       tamper(func(b *pem.Block) { b.Headers["Kdf-Params"] = "N=1048576,r=32,p=1" }),
+			passphrase:  "passphrase",
+			expectedErr: "key derivation parameters 'N=1048576,r=32,p=1' are not supported",
+		},
+		{
+			name:        "should return an error for a too large work",
+			input:       tamper(func(b *pem.Block) { b.Headers["Kdf-Params"] = "N=262144,r=8,p=16" }),
+			passphrase:  "passphrase",
+			expectedErr: "key derivation parameters 'N=262144,r=8,p=16' are not supported",
+		},
+		{
+			name:        "should return an error for unsupported ciphers",
+			input:       tamper(func(b *pem.Block) { b.Headers["Cipher"] = "DES" }),
+			passphrase:  "passphrase",
+			expectedErr: "cipher 'DES' is not supported",
+		},
+		{
+			name:        "should return an error for plain files",
+			input:       []byte("A=1\n"),
+			passphrase:  "passphrase",
+			expectedErr: "the content is not an encrypted env file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.input, []byte(tt.passphrase))
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.True(t, IsEncrypted([]byte("\n"+Header+"\n")))
+	assert.False(t, IsEncrypted([]byte("A=1\n"+Header)))
+	assert.False(t, IsEncrypted(bytes.Repeat([]byte("-"), 5)))
+}
@@ -0,0 +1,152 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// AgeHeader is the first line of files encrypted for recipients, which are age files.
+const AgeHeader = "age-encryption.org/v1"
+
+// recipientsPrefix starts the first line of the encrypted content listing the recipients of the file,
+// since the age header does not record them.
+const recipientsPrefix = "# enve:recipients "
+
+// ErrNoIdentity is returned when none of the given identities is a recipient of a file.
+var ErrNoIdentity = errors.New("none of the identities is a recipient of the file")
+
+// HasRecipients reports whether the given content is an env file encrypted for recipients,
+// either as a binary or an armored age file.
+func HasRecipients(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte(AgeHeader+"\n")) ||
+		bytes.HasPrefix(bytes.TrimLeft(buf, " \t\r\n"), []byte(armor.Header))
+}
+
+// Recipients returns the recipients of an env file encrypted for recipients
+// using the identities (AGE-SECRET-KEY-1...) of the given identity file content,
+// since they are only known once the file is decrypted.
+func Recipients(buf, identities []byte) ([]string, error) {
+	plaintext, err := decryptAge(buf, identities)
+	if err != nil {
+		return nil, err
+	}
+	recipients, _ := splitRecipients(plaintext)
+	if recipients == nil {
+		return nil, errors.New("the recipients of the file are unknown since it was not encrypted by enve")
+	}
+	return recipients, nil
+}
+
+// EncryptFor returns the given content encrypted as a binary age file for every given X25519 recipient (age1...).
+// The recipients are listed in a comment on top of the encrypted content.
+func EncryptFor(plaintext []byte, recipients []string) ([]byte, error) {
+	var list []string
+	var parsed []age.Recipient
+	seen := map[string]bool{}
+	for _, s := range recipients {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("recipient '%s' is invalid", s)
+		}
+		seen[s] = true
+		list = append(list, s)
+		parsed = append(parsed, r)
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, parsed...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, recipientsPrefix+strings.Join(list, ",")+"\n"); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// DecryptWith returns the content of an env file encrypted for recipients
+// using the identities (AGE-SECRET-KEY-1...) of the given identity file content.
+func DecryptWith(buf, identities []byte) ([]byte, error) {
+	plaintext, err := decryptAge(buf, identities)
+	if err != nil {
+		return nil, err
+	}
+	_, content := splitRecipients(plaintext)
+	return content, nil
+}
+
+// SetRecipients returns the given env file encrypted for recipients
+// with its content encrypted again for a new set of recipients.
+// Only the given identities are required, and age uses a new file key on every encryption
+// so removed recipients which kept the previous one cannot decrypt the file.
+func SetRecipients(buf, identities []byte, recipients []string) ([]byte, error) {
+	plaintext, err := DecryptWith(buf, identities)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptFor(plaintext, recipients)
+}
+
+// decryptAge returns the content of a binary or armored age file including its recipients comment.
+func decryptAge(buf, identities []byte) ([]byte, error) {
+	if !HasRecipients(buf) {
+		if IsEncrypted(buf) {
+			return nil, errors.New("the file is encrypted using a passphrase instead of recipients")
+		}
+		return nil, errors.New("the content is not an env file encrypted for recipients")
+	}
+	ids, err := age.ParseIdentities(bytes.NewReader(identities))
+	if err != nil {
+		return nil, fmt.Errorf("identities are invalid.\n%v", err)
+	}
+	var src io.Reader = bytes.NewReader(buf)
+	if !bytes.HasPrefix(buf, []byte(AgeHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimLeft(buf, " \t\r\n")))
+	}
+	r, err := age.Decrypt(src, ids...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, ErrNoIdentity
+		}
+		return nil, ErrDecrypt
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// splitRecipients returns the recipients listed on top of the decrypted content along with the rest of it.
+// Contents without recipients comment, like the ones encrypted via age directly, are returned as is.
+func splitRecipients(plaintext []byte) ([]string, []byte) {
+	line, rest, found := bytes.Cut(plaintext, []byte("\n"))
+	if !found {
+		return nil, plaintext
+	}
+	list, ok := strings.CutPrefix(string(line), recipientsPrefix)
+	if !ok {
+		return nil, plaintext
+	}
+	return strings.Split(list, ","), rest
+}
@@ -0,0 +1,140 @@
+package crypt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	id, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	return id
+}
+
+func TestEncryptFor(t *testing.T) {
+	plaintext := []byte("# Secrets\nDB_PASSWORD=s3cr3t\n")
+	alice, bob, eve := newIdentity(t), newIdentity(t), newIdentity(t)
+
+	buf, err := EncryptFor(plaintext, []string{alice.Recipient().String(), bob.Recipient().String(), alice.Recipient().String()})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(buf, []byte("age-encryption.org/v1\n")))
+	assert.True(t, IsEncrypted(buf))
+	assert.True(t, HasRecipients(buf))
+	assert.NotContains(t, string(buf), "s3cr3t")
+
+	recipients, err := Recipients(buf, []byte(bob.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{alice.Recipient().String(), bob.Recipient().String()}, recipients)
+
+	for _, id := range []*age.X25519Identity{alice, bob} {
+		decrypted, err := DecryptWith(buf, []byte("# identity\n"+id.String()+"\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+
+	_, err = DecryptWith(buf, []byte(eve.String()))
+	assert.ErrorIs(t, err, ErrNoIdentity)
+
+	_, err = Recipients(buf, []byte(eve.String()))
+	assert.ErrorIs(t, err, ErrNoIdentity)
+
+	_, err = Decrypt(buf, []byte("passphrase"))
+	assert.EqualError(t, err, "the file is encrypted for recipients instead of a passphrase")
+
+	_, err = EncryptFor(plaintext, []string{"age1invalid"})
+	assert.EqualError(t, err, "recipient 'age1invalid' is invalid")
+
+	_, err = EncryptFor(plaintext, []string{""})
+	assert.EqualError(t, err, "at least one recipient is required")
+}
+
+func TestEncryptFor_Age(t *testing.T) {
+	plaintext := []byte("A=1\n")
+	id := newIdentity(t)
+
+	t.Run("should be decrypted by age", func(t *testing.T) {
+		buf, err := EncryptFor(plaintext, []string{id.Recipient().String()})
+		assert.NoError(t, err)
+		r, err := age.Decrypt(bytes.NewReader(buf), id)
+		assert.NoError(t, err)
+		decrypted, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "# enve:recipients "+id.Recipient().String()+"\n"+string(plaintext), string(decrypted))
+	})
+
+	t.Run("should decrypt binary and armored files encrypted by age", func(t *testing.T) {
+		var binary, armored bytes.Buffer
+		w, err := age.Encrypt(&binary, id.Recipient())
+		assert.NoError(t, err)
+		_, err = w.Write(plaintext)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		aw := armor.NewWriter(&armored)
+		w, err = age.Encrypt(aw, id.Recipient())
+		assert.NoError(t, err)
+		_, err = w.Write(plaintext)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+		assert.NoError(t, aw.Close())
+
+		for _, buf := range [][]byte{binary.Bytes(), armored.Bytes()} {
+			assert.True(t, HasRecipients(buf))
+			decrypted, err := DecryptWith(buf, []byte(id.String()))
+			assert.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+
+			_, err = Recipients(buf, []byte(id.String()))
+			assert.EqualError(t, err, "the recipients of the file are unknown since it was not encrypted by enve")
+		}
+	})
+}
+
+func TestSetRecipients(t *testing.T) {
+	plaintext := []byte("A=1\n")
+	alice, bob := newIdentity(t), newIdentity(t)
+
+	buf, err := EncryptFor(plaintext, []string{alice.Recipient().String()})
+	assert.NoError(t, err)
+
+	t.Run("should encrypt the file for the new recipients", func(t *testing.T) {
+		rekeyed, err := SetRecipients(buf, []byte(alice.String()), []string{bob.Recipient().String()})
+		assert.NoError(t, err)
+
+		decrypted, err := DecryptWith(rekeyed, []byte(bob.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+
+		recipients, err := Recipients(rekeyed, []byte(bob.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{bob.Recipient().String()}, recipients)
+
+		_, err = DecryptWith(rekeyed, []byte(alice.String()))
+		assert.ErrorIs(t, err, ErrNoIdentity)
+	})
+
+	t.Run("should return an error for an identity which is not a recipient", func(t *testing.T) {
+		_, err := SetRecipients(buf, []byte(bob.String()), []string{bob.Recipient().String()})
+		assert.ErrorIs(t, err, ErrNoIdentity)
+	})
+
+	t.Run("should return an error for a modified file", func(t *testing.T) {
+		modified := bytes.Clone(buf)
+		modified[len(modified)-1] ^= 1
+		_, err := DecryptWith(modified, []byte(alice.String()))
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("should return an error for passphrase encrypted files", func(t *testing.T) {
+		encrypted, err := Encrypt(plaintext, []byte("passphrase"))
+		assert.NoError(t, err)
+		assert.False(t, HasRecipients(encrypted))
+		_, err = SetRecipients(encrypted, []byte(alice.String()), []string{bob.Recipient().String()})
+		assert.EqualError(t, err, "the file is encrypted using a passphrase instead of recipients")
+	})
+}
@@ -0,0 +1,186 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/joseluisq/enve/dotenv"
+)
+
+// ValuePrefix is the prefix of encrypted values.
+const ValuePrefix = "enc:v1:"
+
+// metadataPrefix starts the comment holding the key derivation parameters and the MAC of files with encrypted values.
+const metadataPrefix = "# enve:v1 "
+
+// HasEncryptedValues reports whether the given env file content has encrypted values.
+// Contents with a metadata comment or any value prefixed by ValuePrefix are reported,
+// so the ones whose metadata was removed or duplicated fail to decrypt instead of loading the ciphertexts.
+func HasEncryptedValues(buf []byte) bool {
+	return hasEncryptedValues(dotenv.ParseBytes(buf))
+}
+
+// hasEncryptedValues reports whether the given file has a metadata comment or an encrypted value.
+func hasEncryptedValues(f *dotenv.File) bool {
+	for _, n := range f.Nodes {
+		switch {
+		case n.Kind == dotenv.Comment && strings.HasPrefix(n.Comment, metadataPrefix):
+			return true
+		case n.Kind == dotenv.Variable && strings.HasPrefix(n.RawValue, ValuePrefix):
+			return true
+		}
+	}
+	return false
+}
+
+// metadata returns the index of the metadata comment of a file with encrypted values.
+func metadata(f *dotenv.File) (int, error) {
+	index := -1
+	for i, n := range f.Nodes {
+		if n.Kind == dotenv.Comment && strings.HasPrefix(n.Comment, metadataPrefix) {
+			if index != -1 {
+				return -1, errors.New("the metadata of encrypted values is declared more than once")
+			}
+			index = i
+		}
+	}
+	if index == -1 {
+		return -1, errors.New("the metadata of encrypted values is missing")
+	}
+	return index, nil
+}
+
+// valueKeys returns the keys used to encrypt the values and to compute the MAC of a file.
+func valueKeys(passphrase, salt []byte, n, r, p int) (cipherKey, macKey []byte, err error) {
+	key, err := scrypt.Key(passphrase, salt, n, r, p, 2*keyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key[:keyLen], key[keyLen:], nil
+}
+
+// mac returns the MAC of the whole file content.
+func mac(key []byte, f *dotenv.File) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(f.Bytes())
+	return h.Sum(nil)
+}
+
+// EncryptValues returns the given env file content with its values encrypted
+// while the variable names and comments are kept as plain text.
+// The value of every variable is encrypted as written in the file so it is loaded as before,
+// and a MAC over the whole file is appended as a comment to detect any modification.
+func EncryptValues(buf, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("the passphrase is empty")
+	}
+	f := dotenv.ParseBytes(buf)
+	if err := f.Err(); err != nil {
+		return nil, err
+	}
+	if hasEncryptedValues(f) {
+		return nil, errors.New("the values are already encrypted")
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cipherKey, macKey, err := valueKeys(passphrase, salt, costN, costR, costP)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range f.Variables() {
+		if n.RawValue == "" {
+			continue
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		// NOTE: the variable name is authenticated so values cannot be swapped
+		ciphertext := aead.Seal(nonce, nonce, []byte(n.RawValue), []byte(n.Key))
+		n.SetRawValue(ValuePrefix + base64.RawURLEncoding.EncodeToString(ciphertext))
+	}
+
+	f.FinalNewline = true
+	line := fmt.Sprintf("%skdf=%s params=%s salt=%s mac=%s", metadataPrefix, kdfName, params,
+		base64.StdEncoding.EncodeToString(salt), base64.StdEncoding.EncodeToString(mac(macKey, f)))
+	node := dotenv.ParseBytes([]byte(line)).Nodes[0]
+	if len(f.Nodes) > 0 {
+		node.Line = f.Nodes[len(f.Nodes)-1].EndLine + 1
+		node.EndLine = node.Line
+	}
+	f.Nodes = append(f.Nodes, node)
+	return f.Bytes(), nil
+}
+
+// DecryptValues returns the given env file content with its values decrypted once its MAC is verified.
+func DecryptValues(buf, passphrase []byte) ([]byte, error) {
+	f := dotenv.ParseBytes(buf)
+	index, err := metadata(f)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	for _, field := range strings.Fields(strings.TrimPrefix(f.Nodes[index].Comment, metadataPrefix)) {
+		k, v, _ := strings.Cut(field, "=")
+		fields[k] = v
+	}
+	if k := fields["kdf"]; k != kdfName {
+		return nil, fmt.Errorf("key derivation function '%s' is not supported", k)
+	}
+	n, r, p, err := parseParams(fields["params"])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(fields["salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("salt is invalid")
+	}
+	sum, err := base64.StdEncoding.DecodeString(fields["mac"])
+	if err != nil {
+		return nil, errors.New("MAC is invalid")
+	}
+
+	cipherKey, macKey, err := valueKeys(passphrase, salt, n, r, p)
+	if err != nil {
+		return nil, err
+	}
+	f.Nodes = append(f.Nodes[:index], f.Nodes[index+1:]...)
+	if !hmac.Equal(sum, mac(macKey, f)) {
+		return nil, ErrDecrypt
+	}
+
+	aead, err := newAEAD(cipherKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range f.Variables() {
+		encoded, ok := strings.CutPrefix(node.RawValue, ValuePrefix)
+		if !ok {
+			continue
+		}
+		ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil || len(ciphertext) < aead.NonceSize() {
+			return nil, fmt.Errorf("value of '%s' is invalid", node.Key)
+		}
+		plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], []byte(node.Key))
+		if err != nil {
+			return nil, fmt.Errorf("value of '%s' cannot be decrypted", node.Key)
+		}
+		node.SetRawValue(string(plaintext))
+	}
+	return f.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+package crypt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptValues(t *testing.T) {
+	plaintext := "# Database\r\nexport DB_HOST=localhost # host\r\nDB_URL=\"postgres://${DB_HOST}:5432\"\r\nDB_PASSWORD='s3cr3t $x'\r\nEMPTY=\r\nMULTI=\"a\r\nb\""
+
+	buf, err := EncryptValues([]byte(plaintext), []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.True(t, HasEncryptedValues(buf))
+	assert.False(t, IsEncrypted(buf))
+
+	lines := strings.Split(string(buf), "\r\n")
+	assert.Equal(t, "# Database", lines[0])
+	assert.Regexp(t, `^export DB_HOST=enc:v1:[A-Za-z0-9_-]+ # host$`, lines[1])
+	assert.Regexp(t, `^DB_URL=enc:v1:[A-Za-z0-9_-]+$`, lines[2])
+	assert.NotContains(t, string(buf), "s3cr3t")
+	assert.Equal(t, "EMPTY=", lines[4])
+	assert.Regexp(t, `^# enve:v1 kdf=scrypt params=N=32768,r=8,p=1 salt=\S+ mac=\S+$`, lines[6])
+
+	decrypted, err := DecryptValues(buf, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext+"\r\n", string(decrypted))
+
+	vars, err := godotenv.UnmarshalBytes(decrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres://localhost:5432", vars["DB_URL"])
+
+	_, err = EncryptValues(buf, []byte("passphrase"))
+	assert.EqualError(t, err, "the values are already encrypted")
+
+	_, err = EncryptValues([]byte("A"), []byte("passphrase"))
+	assert.EqualError(t, err, "line 1: missing '=' after variable name")
+}
+
+func TestHasEncryptedValues(t *testing.T) {
+	buf, err := EncryptValues([]byte("A=1\n"), []byte("passphrase"))
+	assert.NoError(t, err)
+	lines := strings.Split(string(buf), "\n")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "should report a file with encrypted values",
+			input:    string(buf),
+			expected: true,
+		},
+		{
+			name:     "should report encrypted values without metadata",
+			input:    lines[0] + "\n",
+			expected: true,
+		},
+		{
+			name:     "should report a duplicated metadata",
+			input:    string(buf) + lines[1] + "\n",
+			expected: true,
+		},
+		{
+			name:     "should not report plain values",
+			input:    "# enve values\nA=enc:v2\n",
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HasEncryptedValues([]byte(tt.input)))
+		})
+	}
+}
+
+func TestDecryptValues(t *testing.T) {
+	buf, err := EncryptValues([]byte("# Secrets\nA=1\nB=2\n"), []byte("passphrase"))
+	assert.NoError(t, err)
+	lines := strings.Split(string(buf), "\n")
+
+	tests := []struct {
+		name        string
+		input       string
+		passphrase  string
+		expectedErr string
+	}{
+		{
+			name:        "should return an error for a wrong passphrase",
+			input:       string(buf),
+			passphrase:  "wrong",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for a modified comment",
+			input:       strings.Replace(string(buf), "# Secrets", "# Secret", 1),
+			passphrase:  "passphrase",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for swapped values",
+			input:       strings.Join([]string{lines[0], "A" + lines[2][1:], "B" + lines[1][1:], lines[3], ""}, "\n"),
+			passphrase:  "passphrase",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for a removed variable",
+			input:       strings.Join([]string{lines[0], lines[1], lines[3], ""}, "\n"),
+			passphrase:  "passphrase",
+			expectedErr: ErrDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for a missing metadata",
+			input:       strings.Join(lines[:3], "\n"),
+			passphrase:  "passphrase",
+			expectedErr: "the metadata of encrypted values is missing",
+		},
+		{
+			name:        "should return an error for a duplicated metadata",
+			input:       string(buf) + lines[3] + "\n",
+			passphrase:  "passphrase",
+			expectedErr: "the metadata of encrypted values is declared more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecryptValues([]byte(tt.input), []byte(tt.passphrase))
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
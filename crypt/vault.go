@@ -0,0 +1,83 @@
+package crypt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// vaultKeyLen is the length of the hex encoded key of dotenv-vault files.
+const vaultKeyLen = 64
+
+// ErrVaultDecrypt is returned when an environment of a dotenv-vault file cannot be authenticated with the given key.
+var ErrVaultDecrypt = errors.New("the key is wrong or the vault was modified")
+
+// DecryptVault returns the content of the environment of a dotenv-vault (.env.vault) file
+// selected by the given key URI like `dotenv://:key_1234…@dotenv.org/vault/.env.vault?environment=production`.
+// Several comma-separated keys can be given, the first one decrypting its environment is used.
+func DecryptVault(buf []byte, keys string) ([]byte, error) {
+	vault, err := godotenv.UnmarshalBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	err = errors.New("no key was provided")
+	for _, k := range strings.Split(keys, ",") {
+		var plaintext []byte
+		if plaintext, err = decryptVaultEnv(vault, strings.TrimSpace(k)); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, err
+}
+
+// decryptVaultEnv decrypts the environment of a vault selected by a single key URI.
+func decryptVaultEnv(vault map[string]string, key string) ([]byte, error) {
+	uri, err := url.Parse(key)
+	if err != nil || uri.Scheme != "dotenv" {
+		return nil, errors.New("key is not a valid dotenv URI")
+	}
+	secret, _ := uri.User.Password()
+	if secret == "" {
+		return nil, errors.New("key is missing its key part")
+	}
+	environment := uri.Query().Get("environment")
+	if environment == "" {
+		return nil, errors.New("key is missing its environment part")
+	}
+
+	name := "DOTENV_VAULT_" + strings.ToUpper(environment)
+	encoded, ok := vault[name]
+	if !ok {
+		return nil, fmt.Errorf("environment '%s' cannot be found in the vault (%s)", environment, name)
+	}
+	// NOTE: the key is made of the last 64 hex characters, usually prefixed by `key_`
+	if len(secret) < vaultKeyLen {
+		return nil, errors.New("key is invalid")
+	}
+	aesKey, err := hex.DecodeString(secret[len(secret)-vaultKeyLen:])
+	if err != nil {
+		return nil, errors.New("key is invalid")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("environment '%s' of the vault is invalid", environment)
+	}
+
+	aead, err := newAEAD(aesKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrVaultDecrypt
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrVaultDecrypt
+	}
+	return plaintext, nil
+}
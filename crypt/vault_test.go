@@ -0,0 +1,84 @@
+package crypt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Keys of the environments found in the vault fixture.
+const (
+	developmentVaultKey = "dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault?environment=development"
+	productionVaultKey  = "dotenv://:key_7f3e2a9c1b5d8e4f6a0c3b2d1e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f@dotenv.org/vault/.env.vault?environment=production"
+)
+
+func TestDecryptVault(t *testing.T) {
+	vault, err := os.ReadFile(filepath.Join("..", "fixtures", "vault", ".env.vault"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		keys        string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "should decrypt the development environment",
+			keys:     developmentVaultKey,
+			expected: "# development@v6\nALPHA=\"zeta\"",
+		},
+		{
+			name:     "should decrypt the production environment",
+			keys:     productionVaultKey,
+			expected: "# production@v2\nHOST=api.example.com\nPORT=443\nURL=\"https://${HOST}:${PORT}\"\n",
+		},
+		{
+			name:     "should use the first key decrypting its environment",
+			keys:     "dotenv://:key_0000000000000000000000000000000000000000000000000000000000000000@dotenv.org/vault/.env.vault?environment=production, " + developmentVaultKey,
+			expected: "# development@v6\nALPHA=\"zeta\"",
+		},
+		{
+			name:        "should return an error for a wrong key",
+			keys:        "dotenv://:key_0000000000000000000000000000000000000000000000000000000000000000@dotenv.org/vault/.env.vault?environment=production",
+			expectedErr: ErrVaultDecrypt.Error(),
+		},
+		{
+			name:        "should return an error for an unknown environment",
+			keys:        "dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault?environment=staging",
+			expectedErr: "environment 'staging' cannot be found in the vault (DOTENV_VAULT_STAGING)",
+		},
+		{
+			name:        "should return an error for a missing key part",
+			keys:        "dotenv://dotenv.org/vault/.env.vault?environment=production",
+			expectedErr: "key is missing its key part",
+		},
+		{
+			name:        "should return an error for a missing environment part",
+			keys:        "dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault",
+			expectedErr: "key is missing its environment part",
+		},
+		{
+			name:        "should return an error for an invalid key",
+			keys:        "dotenv://:key_1234@dotenv.org/vault/.env.vault?environment=production",
+			expectedErr: "key is invalid",
+		},
+		{
+			name:        "should return an error for a key which is not an URI",
+			keys:        "ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00",
+			expectedErr: "key is not a valid dotenv URI",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := DecryptVault(vault, tt.keys)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(plaintext))
+		})
+	}
+}
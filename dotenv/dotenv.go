@@ -0,0 +1,295 @@
+// Package dotenv provides a comment-preserving syntax tree of env files
+// following the same syntax rules as the parser used to load them.
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kind is the type of a node.
+type Kind int
+
+const (
+	// Blank is an empty or whitespace only line.
+	Blank Kind = iota
+	// Comment is a line starting with '#'.
+	Comment
+	// Variable is a variable declaration which can span several lines when quoted.
+	Variable
+	// Invalid is a statement which cannot be parsed.
+	Invalid
+)
+
+// bom is the UTF-8 byte order mark.
+const bom = "\ufeff"
+
+// Node is a statement of an env file.
+type Node struct {
+	Kind Kind
+	// Number of the first line of the node starting at 1.
+	Line int
+	// Number of the last line of the node.
+	EndLine int
+	// Original text of the node lines without line breaks.
+	Raw string
+
+	// Whether the variable is prefixed with `export`.
+	Export bool
+	// Name of the variable.
+	Key string
+	// Separator between the name and the value, either '=' or ':'.
+	Sep byte
+	// Quote character of the value, either 0, '\'' or '"'.
+	Quote byte
+	// Value as written in the file including quotes.
+	RawValue string
+	// Value with quotes removed and escape sequences processed but without expanding references.
+	Value string
+	// Comment of the line (including '#') for comment nodes or trailing a variable value.
+	Comment string
+
+	// Reason why the statement is invalid.
+	Err string
+}
+
+// File is the syntax tree of an env file.
+type File struct {
+	Nodes []*Node
+	// Whether the file starts with a UTF-8 byte order mark.
+	BOM bool
+	// Whether the file uses CRLF line breaks.
+	CRLF bool
+	// Whether the file ends with a line break.
+	FinalNewline bool
+}
+
+// SyntaxError describes an invalid statement of an env file.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse reads an env file into a syntax tree.
+// Invalid statements are kept as Invalid nodes, use File.Err to find out if any.
+func Parse(r io.Reader) (*File, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytes(buf), nil
+}
+
+// ParseBytes parses the given env file content into a syntax tree.
+func ParseBytes(buf []byte) *File {
+	f := &File{}
+	src := string(buf)
+	if strings.HasPrefix(src, bom) {
+		f.BOM = true
+		src = strings.TrimPrefix(src, bom)
+	}
+	if src == "" {
+		return f
+	}
+	f.FinalNewline = strings.HasSuffix(src, "\n")
+	f.CRLF = strings.Contains(src, "\r\n")
+
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+
+	for i := 0; i < len(lines); {
+		n := parseNode(lines, i)
+		f.Nodes = append(f.Nodes, n)
+		i = n.EndLine
+	}
+	return f
+}
+
+// Err returns the first syntax error of the file if any.
+func (f *File) Err() error {
+	for _, n := range f.Nodes {
+		if n.Kind == Invalid {
+			return &SyntaxError{Line: n.Line, Msg: n.Err}
+		}
+	}
+	return nil
+}
+
+// Lookup returns the last declaration of the given variable, which is the one taking effect.
+func (f *File) Lookup(key string) *Node {
+	for i := len(f.Nodes) - 1; i >= 0; i-- {
+		if n := f.Nodes[i]; n.Kind == Variable && n.Key == key {
+			return n
+		}
+	}
+	return nil
+}
+
+// Variables returns the variable nodes in file order.
+func (f *File) Variables() []*Node {
+	var nodes []*Node
+	for _, n := range f.Nodes {
+		if n.Kind == Variable {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// isSpace reports whether the rune is a space character but not a line break.
+func isSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', '\r', ' ', 0x85, 0xA0:
+		return true
+	}
+	return false
+}
+
+// parseNode parses the statement starting at the given line index.
+func parseNode(lines []string, i int) *Node {
+	n := &Node{Line: i + 1, EndLine: i + 1, Raw: lines[i]}
+	text := strings.TrimLeftFunc(lines[i], unicode.IsSpace)
+
+	switch {
+	case text == "":
+		n.Kind = Blank
+		return n
+	case text[0] == '#':
+		n.Kind = Comment
+		n.Comment = strings.TrimRightFunc(text, unicode.IsSpace)
+		return n
+	}
+
+	invalid := func(format string, args ...any) *Node {
+		n.Kind = Invalid
+		n.Err = fmt.Sprintf(format, args...)
+		return n
+	}
+
+	if rest, ok := strings.CutPrefix(text, "export"); ok && rest != "" && isSpace(rune(rest[0])) {
+		n.Export = true
+		text = strings.TrimLeftFunc(rest, isSpace)
+	}
+
+	sep := strings.IndexAny(text, "=:")
+	if sep == -1 {
+		return invalid("missing '=' after variable name")
+	}
+	for _, r := range text[:sep] {
+		if !isSpace(r) && r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			return invalid("unexpected character %q in variable name", r)
+		}
+	}
+	n.Key = strings.TrimRightFunc(text[:sep], isSpace)
+	if n.Key == "" {
+		return invalid("missing variable name")
+	}
+	n.Sep = text[sep]
+	value := strings.TrimLeftFunc(text[sep+1:], isSpace)
+
+	if value == "" || (value[0] != '\'' && value[0] != '"') {
+		// NOTE: inline comments start at the last '#' preceded by a space
+		end := len(value)
+		for j := len(value) - 1; j > 0; j-- {
+			if value[j] == '#' && isSpace(rune(value[j-1])) {
+				end = j
+				break
+			}
+		}
+		if end < len(value) {
+			n.Comment = strings.TrimRightFunc(value[end:], unicode.IsSpace)
+		}
+		n.RawValue = strings.TrimFunc(value[:end], isSpace)
+		n.Value = n.RawValue
+		n.Kind = Variable
+		return n
+	}
+
+	// quoted values may span several lines until the closing quote
+	n.Quote = value[0]
+	end := -1
+	for {
+		for j := 1; j < len(value); j++ {
+			if value[j] == n.Quote && value[j-1] != '\\' {
+				end = j
+				break
+			}
+		}
+		if end != -1 || n.EndLine == len(lines) {
+			break
+		}
+		value += "\n" + lines[n.EndLine]
+		n.Raw += "\n" + lines[n.EndLine]
+		n.EndLine++
+	}
+	if end == -1 {
+		return invalid("unterminated quoted value")
+	}
+
+	n.RawValue = value[:end+1]
+	rest := strings.TrimFunc(value[end+1:], unicode.IsSpace)
+	if rest != "" && rest[0] != '#' {
+		return invalid("unexpected characters %q after quoted value", rest)
+	}
+	n.Comment = rest
+
+	quote := string(n.Quote)
+	n.Value = strings.TrimRight(strings.TrimLeft(n.RawValue, quote), quote)
+	if n.Quote == '"' {
+		n.Value = unescape(n.Value)
+	}
+	n.Kind = Variable
+	return n
+}
+
+var (
+	escapeRegex   = regexp.MustCompile(`\\.`)
+	unescapeRegex = regexp.MustCompile(`\\(.)`)
+)
+
+// unescape processes the escape sequences of a double quoted value.
+func unescape(s string) string {
+	s = escapeRegex.ReplaceAllStringFunc(s, func(match string) string {
+		switch match[1] {
+		case 'n':
+			return "\n"
+		case 'r':
+			return "\r"
+		default:
+			return match
+		}
+	})
+	return unescapeRegex.ReplaceAllString(s, "$1")
+}
+
+// Bytes returns the content of the file using its line breaks.
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	if f.BOM {
+		b.WriteString(bom)
+	}
+	eol := "\n"
+	if f.CRLF {
+		eol = "\r\n"
+	}
+	for i, n := range f.Nodes {
+		if i > 0 {
+			b.WriteString(eol)
+		}
+		b.WriteString(strings.ReplaceAll(n.Raw, "\n", eol))
+	}
+	if f.FinalNewline && len(f.Nodes) > 0 {
+		b.WriteString(eol)
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,120 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []*Node
+	}{
+		{
+			name:  "should parse blank lines and comments",
+			input: "# comment\n\n  \n",
+			expected: []*Node{
+				{Kind: Comment, Line: 1, EndLine: 1, Raw: "# comment", Comment: "# comment"},
+				{Kind: Blank, Line: 2, EndLine: 2, Raw: ""},
+				{Kind: Blank, Line: 3, EndLine: 3, Raw: "  "},
+			},
+		},
+		{
+			name:  "should parse unquoted values with inline comments",
+			input: "export HOST = localhost # the host\nURL: http://x#y\nEMPTY=",
+			expected: []*Node{
+				{Kind: Variable, Line: 1, EndLine: 1, Raw: "export HOST = localhost # the host", Export: true, Key: "HOST", Sep: '=', RawValue: "localhost", Value: "localhost", Comment: "# the host"},
+				{Kind: Variable, Line: 2, EndLine: 2, Raw: "URL: http://x#y", Key: "URL", Sep: ':', RawValue: "http://x#y", Value: "http://x#y"},
+				{Kind: Variable, Line: 3, EndLine: 3, Raw: "EMPTY=", Key: "EMPTY", Sep: '='},
+			},
+		},
+		{
+			name:  "should parse quoted values",
+			input: `A='it is # not a comment' # comment` + "\n" + `B="say \"hi\"\nbye"`,
+			expected: []*Node{
+				{Kind: Variable, Line: 1, EndLine: 1, Raw: `A='it is # not a comment' # comment`, Key: "A", Sep: '=', Quote: '\'', RawValue: `'it is # not a comment'`, Value: "it is # not a comment", Comment: "# comment"},
+				{Kind: Variable, Line: 2, EndLine: 2, Raw: `B="say \"hi\"\nbye"`, Key: "B", Sep: '=', Quote: '"', RawValue: `"say \"hi\"\nbye"`, Value: "say \"hi\"\nbye"},
+			},
+		},
+		{
+			name:  "should parse multiline quoted values",
+			input: "CERT=\"line 1\nline 2\"\nNEXT=1",
+			expected: []*Node{
+				{Kind: Variable, Line: 1, EndLine: 2, Raw: "CERT=\"line 1\nline 2\"", Key: "CERT", Sep: '=', Quote: '"', RawValue: "\"line 1\nline 2\"", Value: "line 1\nline 2"},
+				{Kind: Variable, Line: 3, EndLine: 3, Raw: "NEXT=1", Key: "NEXT", Sep: '=', RawValue: "1", Value: "1"},
+			},
+		},
+		{
+			name:  "should keep invalid statements",
+			input: "no separator\nA B-C=1\n=1\nQ=\"open\nR='x' y",
+			expected: []*Node{
+				{Kind: Invalid, Line: 1, EndLine: 1, Raw: "no separator", Err: "missing '=' after variable name"},
+				{Kind: Invalid, Line: 2, EndLine: 2, Raw: "A B-C=1", Err: "unexpected character '-' in variable name"},
+				{Kind: Invalid, Line: 3, EndLine: 3, Raw: "=1", Err: "missing variable name"},
+				{Kind: Invalid, Line: 4, EndLine: 5, Raw: "Q=\"open\nR='x' y", Key: "Q", Sep: '=', Quote: '"', Err: "unterminated quoted value"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ParseBytes([]byte(tt.input))
+			assert.Equal(t, tt.expected, f.Nodes)
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Run("should detect the file encoding details", func(t *testing.T) {
+		f := ParseBytes([]byte("\ufeffA=1\r\nB=2\r\n"))
+		assert.True(t, f.BOM)
+		assert.True(t, f.CRLF)
+		assert.True(t, f.FinalNewline)
+		assert.Equal(t, "1", f.Lookup("A").Value)
+	})
+
+	t.Run("should write the content back", func(t *testing.T) {
+		for _, input := range []string{"", "A=1", "\ufeff# c\r\nA=\"x\r\ny\" # c\r\n\r\n", "A=1\n\nB=2\n"} {
+			assert.Equal(t, input, string(ParseBytes([]byte(input)).Bytes()))
+		}
+	})
+
+	t.Run("should return the first syntax error", func(t *testing.T) {
+		f, err := Parse(strings.NewReader("A=1\nB\nC"))
+		assert.NoError(t, err)
+		assert.EqualError(t, f.Err(), "line 2: missing '=' after variable name")
+		assert.NoError(t, ParseBytes([]byte("A=1")).Err())
+	})
+
+	t.Run("should lookup the last declaration of a variable", func(t *testing.T) {
+		f := ParseBytes([]byte("A=1\nB=2\nA=3"))
+		assert.Equal(t, 3, f.Lookup("A").Line)
+		assert.Nil(t, f.Lookup("C"))
+		assert.Len(t, f.Variables(), 3)
+	})
+}
+
+func TestParseBytes_Compatibility(t *testing.T) {
+	input := "A=1\n" +
+		"export B = two words # comment\n" +
+		"C: yaml\n" +
+		"D='single # quoted'\n" +
+		"E=\"double \\\"quoted\\\" \\\\n \\n end\"\n" +
+		"F=\"multi\nline\"\n" +
+		"G=a#b #c\n" +
+		"H=\n" +
+		"I=\"\\$HOME\"\n"
+
+	expected, err := godotenv.Unmarshal(input)
+	assert.NoError(t, err)
+
+	actual := map[string]string{}
+	for _, n := range ParseBytes([]byte(input)).Variables() {
+		actual[n.Key] = n.Value
+	}
+	assert.Equal(t, expected, actual)
+}
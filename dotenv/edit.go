@@ -0,0 +1,114 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidName reports whether the given variable name is a valid POSIX name.
+func ValidName(key string) bool {
+	return posixNameRegex.MatchString(key)
+}
+
+// Quote returns the given value written so that loading it gives back the same value.
+// Values are double quoted when needed, falling back to single quotes or no quotes
+// for the ones the env file parser cannot read back from double quotes.
+func Quote(value string) (string, error) {
+	if value == "" {
+		return `""`, nil
+	}
+	if bare(value) {
+		return value, nil
+	}
+
+	// NOTE: the closing quote is not detected after a backslash and trailing quotes are trimmed
+	if !strings.HasSuffix(value, "\\") && !strings.HasSuffix(value, `"`) {
+		var b strings.Builder
+		b.WriteByte('"')
+		for _, r := range value {
+			switch r {
+			case '\\', '"', '$':
+				b.WriteByte('\\')
+				b.WriteRune(r)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			default:
+				b.WriteRune(r)
+			}
+		}
+		b.WriteByte('"')
+		return b.String(), nil
+	}
+
+	if !strings.ContainsAny(value, "'\n\r") && !strings.HasSuffix(value, "\\") {
+		return "'" + value + "'", nil
+	}
+
+	// NOTE: unquoted values are trimmed, cut at inline comments and only unescape '$'
+	if !strings.ContainsAny(value, "\n\r") && value[0] != '\'' && value[0] != '"' &&
+		strings.TrimFunc(value, isSpace) == value && !strings.Contains(value, " #") && !strings.Contains(value, "\t#") {
+		return strings.ReplaceAll(value, "$", `\$`), nil
+	}
+	return "", fmt.Errorf("value %q cannot be written to an env file", value)
+}
+
+// Set changes the value of the given variable in place keeping its comment,
+// or appends a new declaration following the `export` usage of the first variable.
+func (f *File) Set(key, value string) error {
+	if !ValidName(key) {
+		return fmt.Errorf("'%s' is not a valid POSIX variable name", key)
+	}
+	quoted, err := Quote(value)
+	if err != nil {
+		return err
+	}
+
+	n := f.Lookup(key)
+	if n == nil {
+		prefix := ""
+		if vars := f.Variables(); len(vars) > 0 && vars[0].Export {
+			prefix = "export "
+		}
+		n = parseNode([]string{prefix + key + "=" + quoted}, 0)
+		if len(f.Nodes) > 0 {
+			n.Line = f.Nodes[len(f.Nodes)-1].EndLine + 1
+			n.EndLine = n.Line
+		}
+		f.Nodes = append(f.Nodes, n)
+		f.FinalNewline = true
+		return nil
+	}
+
+	n.SetRawValue(quoted)
+	return nil
+}
+
+// SetRawValue replaces the value as written in the file keeping the rest of the declaration.
+// The value must be quoted as needed, see Quote.
+func (n *Node) SetRawValue(raw string) {
+	// NOTE: keep everything before the value as written, keys cannot contain separators
+	first := strings.SplitN(n.Raw, "\n", 2)[0]
+	rest := first[strings.IndexAny(first, "=:")+1:]
+	text := first[:len(first)-len(strings.TrimLeftFunc(rest, isSpace))] + raw
+	if n.Comment != "" {
+		text += " " + n.Comment
+	}
+	updated := parseNode(strings.Split(text, "\n"), 0)
+	updated.Line, updated.EndLine = n.Line, n.Line+updated.EndLine-1
+	*n = *updated
+}
+
+// Unset removes all the declarations of the given variable and reports whether there was any.
+func (f *File) Unset(key string) bool {
+	nodes := f.Nodes[:0]
+	for _, n := range f.Nodes {
+		if n.Kind != Variable || n.Key != key {
+			nodes = append(nodes, n)
+		}
+	}
+	removed := len(nodes) < len(f.Nodes)
+	f.Nodes = nodes
+	return removed
+}
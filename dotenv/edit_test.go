@@ -0,0 +1,157 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "should not quote simple values",
+			value:    "localhost:8080/path?a=1",
+			expected: "localhost:8080/path?a=1",
+		},
+		{
+			name:     "should quote empty values",
+			value:    "",
+			expected: `""`,
+		},
+		{
+			name:     "should double quote values with whitespace or comments",
+			value:    "my app # v1",
+			expected: `"my app # v1"`,
+		},
+		{
+			name:     "should escape quotes, backslashes, references and line breaks",
+			value:    "say \"hi\" to C:\\Users and $HOME\r\nbye",
+			expected: `"say \"hi\" to C:\\Users and \$HOME\r\nbye"`,
+		},
+		{
+			name:     "should escape literal escape sequences",
+			value:    `a\nb`,
+			expected: `"a\\nb"`,
+		},
+		{
+			name:     "should single quote values ending with a quote",
+			value:    `say "hi $USER"`,
+			expected: `'say "hi $USER"'`,
+		},
+		{
+			name:     "should not quote values ending with a backslash",
+			value:    `C:\$Temp\`,
+			expected: `C:\\$Temp\`,
+		},
+		{
+			name:     "should not quote values with single quotes ending with a quote",
+			value:    `it's "ok"`,
+			expected: `it's "ok"`,
+		},
+		{
+			name:        "should return an error when the value cannot be written",
+			value:       "it's \"a\nb\"",
+			expectedErr: `value "it's \"a\nb\"" cannot be written to an env file`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quoted, err := Quote(tt.value)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, quoted)
+
+			vars, err := godotenv.Unmarshal("KEY=" + quoted + " # comment\n")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, vars["KEY"])
+		})
+	}
+}
+
+func TestFile_Set(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		key         string
+		value       string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "should change a value keeping its formatting and comment",
+			input:    "# App\r\nexport  HOST : localhost # host\r\nPORT=80\r\n",
+			key:      "HOST",
+			value:    "my host",
+			expected: "# App\r\nexport  HOST : \"my host\" # host\r\nPORT=80\r\n",
+		},
+		{
+			name:     "should change the last declaration of a variable",
+			input:    "A=1\nA=2\nB=3",
+			key:      "A",
+			value:    "4",
+			expected: "A=1\nA=4\nB=3",
+		},
+		{
+			name:     "should change a multiline value",
+			input:    "A=\"multi\nline\" # note\nB=2\n",
+			key:      "A",
+			value:    "",
+			expected: "A=\"\" # note\nB=2\n",
+		},
+		{
+			name:     "should append a new variable following the export usage",
+			input:    "export A=1",
+			key:      "B",
+			value:    "2",
+			expected: "export A=1\nexport B=2\n",
+		},
+		{
+			name:     "should append a new variable to an empty file",
+			input:    "",
+			key:      "A",
+			value:    "1",
+			expected: "A=1\n",
+		},
+		{
+			name:        "should return an error for invalid variable names",
+			input:       "A=1\n",
+			key:         "1A",
+			value:       "1",
+			expectedErr: "'1A' is not a valid POSIX variable name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ParseBytes([]byte(tt.input))
+			err := f.Set(tt.key, tt.value)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(f.Bytes()))
+			assert.Equal(t, tt.value, f.Lookup(tt.key).Value)
+
+			vars, err := godotenv.Parse(strings.NewReader(tt.expected))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, vars[tt.key])
+		})
+	}
+}
+
+func TestFile_Unset(t *testing.T) {
+	f := ParseBytes([]byte("# A\nA=1\nB=2 # b\nA=\"multi\nline\"\n"))
+	assert.True(t, f.Unset("A"))
+	assert.Equal(t, "# A\nB=2 # b\n", string(f.Bytes()))
+	assert.False(t, f.Unset("A"))
+}
@@ -0,0 +1,209 @@
+package dotenv
+
+import (
+	"strings"
+)
+
+// FormatOptions configures how env files get formatted.
+type FormatOptions struct {
+	// Sort the variables alphabetically within blocks separated by blank lines.
+	Sort bool
+}
+
+// Format returns the normalized content of the given env file keeping its comments and ordering:
+//
+//   - Variables are declared as `KEY=value` following the `export` usage of the first one.
+//   - Values are only quoted when needed.
+//   - Inline comments are separated from values by a single space.
+//   - Consecutive blank lines are collapsed and comment groups are preceded by a blank line.
+//   - Byte order marks are removed and lines end with LF.
+//
+// Files containing invalid statements cannot be formatted.
+func Format(f *File, opts FormatOptions) ([]byte, error) {
+	if err := f.Err(); err != nil {
+		return nil, err
+	}
+
+	export := false
+	if vars := f.Variables(); len(vars) > 0 {
+		export = vars[0].Export
+	}
+
+	var lines []string
+	for _, block := range blocks(f.Nodes) {
+		if opts.Sort {
+			block = sortBlock(block)
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		var prev *Node
+		for _, n := range block {
+			// NOTE: start a new comment group after variables
+			if n.Kind == Comment && prev != nil && prev.Kind == Variable {
+				lines = append(lines, "")
+			}
+			lines = append(lines, n.format(export))
+			prev = n
+		}
+	}
+	if len(lines) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// blocks splits the nodes into groups separated by blank lines.
+func blocks(nodes []*Node) [][]*Node {
+	var groups [][]*Node
+	var block []*Node
+	for _, n := range nodes {
+		if n.Kind == Blank {
+			if len(block) > 0 {
+				groups = append(groups, block)
+				block = nil
+			}
+			continue
+		}
+		block = append(block, n)
+	}
+	if len(block) > 0 {
+		groups = append(groups, block)
+	}
+	return groups
+}
+
+// sortBlock sorts the variables of a block alphabetically along with the comments preceding them.
+// Comments heading the block as well as the ones trailing it keep their position,
+// while variables referenced by others are kept before them so their expansion does not change.
+func sortBlock(block []*Node) []*Node {
+	i := 0
+	for i < len(block) && block[i].Kind == Comment {
+		i++
+	}
+	head, block := block[:i], block[i:]
+
+	type entry struct {
+		node  *Node
+		nodes []*Node
+		deps  map[int]bool
+	}
+	var entries []*entry
+	var pending []*Node
+	for _, n := range block {
+		pending = append(pending, n)
+		if n.Kind == Variable {
+			entries = append(entries, &entry{node: n, nodes: pending, deps: map[int]bool{}})
+			pending = nil
+		}
+	}
+	// NOTE: references only expand variables declared before, so keep their relative order
+	for j, e := range entries {
+		refs := e.node.references()
+		for k, other := range entries {
+			if k != j && refs[other.node.Key] {
+				if k < j {
+					e.deps[k] = true
+				} else {
+					other.deps[j] = true
+				}
+			}
+		}
+	}
+
+	sorted := append([]*Node{}, head...)
+	done := map[int]bool{}
+	for len(done) < len(entries) {
+		next := -1
+		for j, e := range entries {
+			if done[j] {
+				continue
+			}
+			ready := true
+			for d := range e.deps {
+				ready = ready && done[d]
+			}
+			if ready && (next == -1 || e.node.Key < entries[next].node.Key) {
+				next = j
+			}
+		}
+		done[next] = true
+		sorted = append(sorted, entries[next].nodes...)
+	}
+	return append(sorted, pending...)
+}
+
+// references returns the names of the variables expanded by the value.
+func (n *Node) references() map[string]bool {
+	refs := map[string]bool{}
+	if n.Quote == '\'' {
+		return refs
+	}
+	for _, m := range referenceRegex.FindAllStringSubmatch(n.RawValue, -1) {
+		if m[1] == "" && m[3] == "" && m[4] != "" {
+			refs[m[4]] = true
+		}
+	}
+	return refs
+}
+
+// format returns the normalized text of a node.
+func (n *Node) format(export bool) string {
+	if n.Kind != Variable {
+		return strings.TrimSpace(n.Raw)
+	}
+	var b strings.Builder
+	if export {
+		b.WriteString("export ")
+	}
+	b.WriteString(n.Key)
+	b.WriteByte('=')
+	value := n.normalizedValue()
+	if value == "" && n.Comment != "" {
+		// NOTE: a comment right after the separator would become the value
+		value = `""`
+	}
+	b.WriteString(value)
+	if n.Comment != "" {
+		b.WriteString(" " + n.Comment)
+	}
+	return b.String()
+}
+
+// bare reports whether the value can be written without quotes and still mean the same.
+func bare(value string) bool {
+	return !strings.ContainsAny(value, " \t\r\n\v\f#'\"\\$")
+}
+
+// normalizedValue returns the value only quoted when needed keeping its meaning.
+func (n *Node) normalizedValue() string {
+	switch n.Quote {
+	case '\'', '"':
+		if bare(n.Value) {
+			return n.Value
+		}
+		return n.RawValue
+	}
+
+	// NOTE: values ending with a backslash or a quote cannot be quoted reliably
+	if !strings.ContainsAny(n.RawValue, " \t#") || strings.HasSuffix(n.RawValue, "\\") || strings.HasSuffix(n.RawValue, `"`) {
+		return n.RawValue
+	}
+	// NOTE: backslashes are only literal in unquoted values unless escaping a '$'
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(n.RawValue); i++ {
+		switch c := n.RawValue[i]; {
+		case c == '\\' && i+1 < len(n.RawValue) && n.RawValue[i+1] == '$':
+			b.WriteString(`\$`)
+			i++
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
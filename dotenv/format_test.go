@@ -0,0 +1,80 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		opts        FormatOptions
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "should format an empty file",
+			input:    "\n\n",
+			expected: "",
+		},
+		{
+			name:     "should normalize spacing, separators and line breaks",
+			input:    "\ufeff  # App  \r\nHOST = localhost\r\nPORT: 8080   # port  \r\n\r\n\r\n\r\nDEBUG=true",
+			expected: "# App\nHOST=localhost\nPORT=8080 # port\n\nDEBUG=true\n",
+		},
+		{
+			name:     "should follow the export usage of the first variable",
+			input:    "export A=1\nB=2\n",
+			expected: "export A=1\nexport B=2\n",
+		},
+		{
+			name: "should only quote values when needed",
+			input: "A=\"simple\"\nB='simple'\nC=two words\nD=a#b\nE=\"${A} b\"\nF='$A'\nG=\"\" # empty\n" +
+				"H=say \"hi\"\nI=a\\b c\nJ=\"line\\nbreak\"\nK=\"multi\nline\"\n",
+			expected: "A=simple\nB=simple\nC=\"two words\"\nD=\"a#b\"\nE=\"${A} b\"\nF='$A'\nG=\"\" # empty\n" +
+				"H=say \"hi\"\nI=\"a\\\\b c\"\nJ=\"line\\nbreak\"\nK=\"multi\nline\"\n",
+		},
+		{
+			name:     "should separate comment groups by a blank line",
+			input:    "# Server\nHOST=localhost\n# Database\nDB_HOST=localhost\n",
+			expected: "# Server\nHOST=localhost\n\n# Database\nDB_HOST=localhost\n",
+		},
+		{
+			name:     "should sort variables within blocks keeping comments",
+			input:    "# Header\nC=3\n# about B\nB=2\nA=1\n# trailing\n\nZ=${Y}\nY=1\nX=${Z}\n",
+			opts:     FormatOptions{Sort: true},
+			expected: "# Header\nA=1\n\n# about B\nB=2\nC=3\n\n# trailing\n\nZ=${Y}\nX=${Z}\nY=1\n",
+		},
+		{
+			name:        "should return an error for invalid statements",
+			input:       "A=1\nB\n",
+			expectedErr: "line 2: missing '=' after variable name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Format(ParseBytes([]byte(tt.input)), tt.opts)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+
+			again, err := Format(ParseBytes(actual), tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, string(actual), string(again), "formatting should be idempotent")
+
+			expectedVars, err := godotenv.Unmarshal(strings.TrimPrefix(tt.input, "\ufeff"))
+			assert.NoError(t, err)
+			actualVars, err := godotenv.Unmarshal(string(actual))
+			assert.NoError(t, err)
+			assert.Equal(t, expectedVars, actualVars, "formatting should keep the values")
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package dotenv
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity is the importance of a lint finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rule describes a lint check.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+}
+
+// Rules contains all the lint checks.
+var Rules = []Rule{
+	{ID: "syntax-error", Severity: SeverityError, Description: "Statement cannot be parsed"},
+	{ID: "duplicate-key", Severity: SeverityError, Description: "Variable is declared more than once, so only the last value takes effect"},
+	{ID: "invalid-name", Severity: SeverityError, Description: "Variable name is not a valid POSIX name"},
+	{ID: "unquoted-space", Severity: SeverityWarning, Description: "Unquoted value contains whitespace"},
+	{ID: "unquoted-hash", Severity: SeverityWarning, Description: "Unquoted value contains '#' which may be read as a comment"},
+	{ID: "trailing-whitespace", Severity: SeverityWarning, Description: "Line ends with whitespace"},
+	{ID: "crlf", Severity: SeverityWarning, Description: "Line ends with a CRLF line break"},
+	{ID: "bom", Severity: SeverityWarning, Description: "File starts with a UTF-8 byte order mark"},
+	{ID: "export-inconsistent", Severity: SeverityWarning, Description: "Variables are declared with and without the export prefix"},
+	{ID: "unresolved-reference", Severity: SeverityWarning, Description: "Reference to a variable not declared earlier in the file expands to an empty string"},
+	{ID: "key-case", Severity: SeverityInfo, Description: "Variable name is not in UPPER_SNAKE_CASE"},
+}
+
+// LookupRule returns the lint check of the given ID.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Finding is a problem reported by a lint check.
+type Finding struct {
+	Line     int      `json:"line" xml:"line,attr"`
+	Rule     string   `json:"rule" xml:"rule,attr"`
+	Severity Severity `json:"severity" xml:"severity,attr"`
+	Message  string   `json:"message" xml:",chardata"`
+}
+
+var (
+	posixNameRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	upperSnakeRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	// NOTE: same references expanded by the env file parser
+	referenceRegex = regexp.MustCompile(`(\\)?(\$)(\()?\{?([A-Z0-9_]+)?\}?`)
+)
+
+// sortedKeys returns the keys of a set in alphabetical order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Lint checks the given env file content and returns the findings ordered by line.
+// Checks with the given IDs are skipped.
+func Lint(buf []byte, disabled ...string) []Finding {
+	skip := map[string]bool{}
+	for _, id := range disabled {
+		skip[id] = true
+	}
+
+	findings := []Finding{}
+	report := func(line int, id string, format string, args ...any) {
+		if skip[id] {
+			return
+		}
+		rule, _ := LookupRule(id)
+		findings = append(findings, Finding{
+			Line:     line,
+			Rule:     id,
+			Severity: rule.Severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	f := ParseBytes(buf)
+	if f.BOM {
+		report(1, "bom", "file starts with a UTF-8 byte order mark")
+	}
+
+	src := strings.TrimPrefix(string(buf), bom)
+	for i, line := range strings.Split(src, "\n") {
+		if strings.HasSuffix(line, "\r") {
+			report(i+1, "crlf", "line ends with a CRLF line break")
+		}
+	}
+
+	declared := map[string]int{}
+	var exportLine, noExportLine int
+	for _, n := range f.Nodes {
+		// NOTE: whitespace in the middle of a multiline value is part of it
+		lines := strings.Split(n.Raw, "\n")
+		if last := lines[len(lines)-1]; strings.TrimRight(last, " \t") != last {
+			report(n.EndLine, "trailing-whitespace", "line ends with whitespace")
+		}
+
+		switch n.Kind {
+		case Invalid:
+			report(n.Line, "syntax-error", "%s", n.Err)
+			continue
+		case Variable:
+		default:
+			continue
+		}
+
+		if line, ok := declared[n.Key]; ok {
+			report(n.Line, "duplicate-key", "duplicate key '%s' previously declared at line %d", n.Key, line)
+		}
+
+		if !posixNameRegex.MatchString(n.Key) {
+			report(n.Line, "invalid-name", "'%s' is not a valid POSIX variable name", n.Key)
+		} else if !upperSnakeRegex.MatchString(n.Key) {
+			report(n.Line, "key-case", "'%s' is not in UPPER_SNAKE_CASE", n.Key)
+		}
+
+		if n.Quote == 0 {
+			if strings.ContainsAny(n.RawValue, " \t") {
+				report(n.Line, "unquoted-space", "unquoted value of '%s' contains whitespace", n.Key)
+			}
+			if strings.Contains(n.RawValue, "#") {
+				report(n.Line, "unquoted-hash", "unquoted value of '%s' contains '#'", n.Key)
+			}
+		}
+
+		if n.Export {
+			if noExportLine > 0 {
+				report(n.Line, "export-inconsistent", "'%s' is declared with export unlike line %d", n.Key, noExportLine)
+			} else if exportLine == 0 {
+				exportLine = n.Line
+			}
+		} else {
+			if exportLine > 0 {
+				report(n.Line, "export-inconsistent", "'%s' is declared without export unlike line %d", n.Key, exportLine)
+			} else if noExportLine == 0 {
+				noExportLine = n.Line
+			}
+		}
+
+		for _, ref := range sortedKeys(n.references()) {
+			if _, ok := declared[ref]; !ok {
+				report(n.Line, "unresolved-reference", "reference to '%s' in '%s' is not declared earlier in the file", ref, n.Key)
+			}
+		}
+
+		if _, ok := declared[n.Key]; !ok {
+			declared[n.Key] = n.Line
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
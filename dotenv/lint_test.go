@@ -0,0 +1,78 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		disabled []string
+		expected []Finding
+	}{
+		{
+			name:     "should report no findings for a valid file",
+			input:    "# config\nHOST=localhost\nNAME=\"my app\"\nURL=http://${HOST}\n",
+			expected: []Finding{},
+		},
+		{
+			name:  "should report encoding problems",
+			input: "\ufeffA=1 \r\nB=2\n",
+			expected: []Finding{
+				{Line: 1, Rule: "bom", Severity: SeverityWarning, Message: "file starts with a UTF-8 byte order mark"},
+				{Line: 1, Rule: "crlf", Severity: SeverityWarning, Message: "line ends with a CRLF line break"},
+				{Line: 1, Rule: "trailing-whitespace", Severity: SeverityWarning, Message: "line ends with whitespace"},
+			},
+		},
+		{
+			name:  "should report invalid declarations",
+			input: "A=1\nA=2\nmy.key=1\napiKey=x\nbad\n",
+			expected: []Finding{
+				{Line: 2, Rule: "duplicate-key", Severity: SeverityError, Message: "duplicate key 'A' previously declared at line 1"},
+				{Line: 3, Rule: "invalid-name", Severity: SeverityError, Message: "'my.key' is not a valid POSIX variable name"},
+				{Line: 4, Rule: "key-case", Severity: SeverityInfo, Message: "'apiKey' is not in UPPER_SNAKE_CASE"},
+				{Line: 5, Rule: "syntax-error", Severity: SeverityError, Message: "missing '=' after variable name"},
+			},
+		},
+		{
+			name:  "should report ambiguous values",
+			input: "A=hello world\nB=a#b\nC='a b#c'\nD=${A}$B\\$E$(F)${G}\nE='${G}'\n",
+			expected: []Finding{
+				{Line: 1, Rule: "unquoted-space", Severity: SeverityWarning, Message: "unquoted value of 'A' contains whitespace"},
+				{Line: 2, Rule: "unquoted-hash", Severity: SeverityWarning, Message: "unquoted value of 'B' contains '#'"},
+				{Line: 4, Rule: "unresolved-reference", Severity: SeverityWarning, Message: "reference to 'G' in 'D' is not declared earlier in the file"},
+			},
+		},
+		{
+			name:  "should report export inconsistencies",
+			input: "export A=1\nB=2\nexport C=3\n",
+			expected: []Finding{
+				{Line: 2, Rule: "export-inconsistent", Severity: SeverityWarning, Message: "'B' is declared without export unlike line 1"},
+			},
+		},
+		{
+			name:     "should skip disabled rules",
+			input:    "\ufeffa=1\r\n",
+			disabled: []string{"bom", "crlf", "key-case"},
+			expected: []Finding{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Lint([]byte(tt.input), tt.disabled...))
+		})
+	}
+}
+
+func TestLookupRule(t *testing.T) {
+	rule, ok := LookupRule("duplicate-key")
+	assert.True(t, ok)
+	assert.Equal(t, SeverityError, rule.Severity)
+
+	_, ok = LookupRule("xyz")
+	assert.False(t, ok)
+}
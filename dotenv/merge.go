@@ -0,0 +1,90 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prefer is the strategy to resolve variables declared with different values in merged files.
+type Prefer string
+
+const (
+	// PreferLeft keeps the values of the base file.
+	PreferLeft Prefer = "left"
+	// PreferRight takes the values of the merged file.
+	PreferRight Prefer = "right"
+	// PreferError fails when values differ.
+	PreferError Prefer = "error-on-conflict"
+)
+
+// ConflictError describes the variables declared with different values in merged files.
+type ConflictError struct {
+	Keys []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting values for %s", "'"+strings.Join(e.Keys, "', '")+"'")
+}
+
+// Merge merges the variables of the other file into this one keeping its comments, ordering and formatting.
+// Variables only declared in the other file are appended in their order while the ones declared
+// in both files with different values are resolved according to the given strategy.
+func (f *File) Merge(other *File, prefer Prefer) error {
+	switch prefer {
+	case PreferLeft, PreferRight, PreferError:
+	default:
+		return fmt.Errorf("merge strategy '%s' is not supported", prefer)
+	}
+	if err := f.Err(); err != nil {
+		return err
+	}
+	if err := other.Err(); err != nil {
+		return err
+	}
+
+	var conflicts []string
+	var appended []*Node
+	for _, n := range other.Variables() {
+		// NOTE: only the last declaration takes effect
+		if other.Lookup(n.Key) != n {
+			continue
+		}
+		current := f.Lookup(n.Key)
+		if current == nil {
+			appended = append(appended, n)
+			continue
+		}
+		if current.sameValue(n) {
+			continue
+		}
+		switch prefer {
+		case PreferRight:
+			current.SetRawValue(n.RawValue)
+		case PreferError:
+			conflicts = append(conflicts, n.Key)
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Keys: conflicts}
+	}
+
+	for _, n := range appended {
+		line := 1
+		if len(f.Nodes) > 0 {
+			line = f.Nodes[len(f.Nodes)-1].EndLine + 1
+		}
+		n := *n
+		n.Line, n.EndLine = line, line+n.EndLine-n.Line
+		f.Nodes = append(f.Nodes, &n)
+		f.FinalNewline = true
+	}
+	return nil
+}
+
+// sameValue reports whether both variables have the same value regardless of how it is quoted.
+func (n *Node) sameValue(other *Node) bool {
+	if len(n.references()) == 0 && len(other.references()) == 0 {
+		return n.Value == other.Value
+	}
+	return n.normalizedValue() == other.normalizedValue()
+}
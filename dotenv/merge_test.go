@@ -0,0 +1,81 @@
+package dotenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Merge(t *testing.T) {
+	base := "# Base\nexport HOST=localhost # host\nPORT=80\nNAME=\"my app\"\n\n# Debug\nDEBUG=true\n"
+	tests := []struct {
+		name        string
+		base        string
+		other       string
+		prefer      Prefer
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "should append new variables and take the values of the other file",
+			base:     base,
+			other:    "# Other\nPORT=8080\nNAME='my app'\nWORKERS=4 # workers\nURL=\"multi\nline\"\n",
+			prefer:   PreferRight,
+			expected: "# Base\nexport HOST=localhost # host\nPORT=8080\nNAME=\"my app\"\n\n# Debug\nDEBUG=true\nWORKERS=4 # workers\nURL=\"multi\nline\"\n",
+		},
+		{
+			name:     "should keep the values of the base file",
+			base:     base,
+			other:    "HOST=example.com\nPORT=8080\nWORKERS=4",
+			prefer:   PreferLeft,
+			expected: base + "WORKERS=4\n",
+		},
+		{
+			name:     "should take the last declaration of the other file",
+			base:     "A=1",
+			other:    "A=2\nA=3\n",
+			prefer:   PreferRight,
+			expected: "A=3",
+		},
+		{
+			name:     "should not report conflicts for equal values",
+			base:     "A=\"x y\"\nB=${A}\n",
+			other:    "A='x y'\nB=${A} # same\n",
+			prefer:   PreferError,
+			expected: "A=\"x y\"\nB=${A}\n",
+		},
+		{
+			name:        "should return an error for conflicting values",
+			base:        base,
+			other:       "HOST=example.com\nPORT=80\nDEBUG='$DEBUG'\n",
+			prefer:      PreferError,
+			expectedErr: "conflicting values for 'HOST', 'DEBUG'",
+		},
+		{
+			name:        "should return an error for invalid files",
+			base:        base,
+			other:       "A",
+			prefer:      PreferRight,
+			expectedErr: "line 1: missing '=' after variable name",
+		},
+		{
+			name:        "should return an error for unsupported strategies",
+			base:        base,
+			other:       "",
+			prefer:      "both",
+			expectedErr: "merge strategy 'both' is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ParseBytes([]byte(tt.base))
+			err := f.Merge(ParseBytes([]byte(tt.other)), tt.prefer)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(f.Bytes()))
+		})
+	}
+}
@@ -2,14 +2,54 @@
 package env
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/joho/godotenv"
+	"github.com/joseluisq/enve/crypt"
 	"github.com/joseluisq/enve/fs"
 )
 
+// KeyEnvVar is the variable containing the passphrase of encrypted env files.
+const KeyEnvVar = "ENVE_KEY"
+
+// DecryptionKey returns the passphrase used to decrypt the given encrypted env file.
+// It defaults to the value of the ENVE_KEY variable.
+var DecryptionKey = func(filePath string) ([]byte, error) {
+	if key, ok := os.LookupEnv(KeyEnvVar); ok && key != "" {
+		return []byte(key), nil
+	}
+	return nil, fmt.Errorf("error: file '%s' is encrypted, provide its passphrase via %s", filePath, KeyEnvVar)
+}
+
+// IdentityEnvVar is the variable containing the path of the identity file
+// used to decrypt env files encrypted for recipients.
+const IdentityEnvVar = "ENVE_IDENTITY"
+
+// DecryptionIdentity returns the identity file content used to decrypt the given env file encrypted for recipients.
+// It defaults to the file of the ENVE_IDENTITY variable.
+var DecryptionIdentity = func(filePath string) ([]byte, error) {
+	path := os.Getenv(IdentityEnvVar)
+	if path == "" {
+		return nil, fmt.Errorf("error: file '%s' is encrypted for recipients, provide an identity file via %s", filePath, IdentityEnvVar)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot read identity file '%s'.\n%v", path, err)
+	}
+	return buf, nil
+}
+
+// VaultKeyEnvVar is the variable containing the key URIs of dotenv-vault (.env.vault) files.
+const VaultKeyEnvVar = "DOTENV_KEY"
+
+// vaultFileName is the name of dotenv-vault files.
+const vaultFileName = ".env.vault"
+
 type EnvironmentVar struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -20,6 +60,15 @@ type Environment struct {
 	Env []EnvironmentVar `json:"environment"`
 }
 
+// Slice returns the variables as key=value pairs.
+func (e Environment) Slice() Slice {
+	vars := Slice{}
+	for _, v := range e.Env {
+		vars = append(vars, v.Name+"="+v.Value)
+	}
+	return vars
+}
+
 type EnvFile interface {
 	Load(overload bool) error
 	Parse() (Map, error)
@@ -32,49 +81,94 @@ type EnvReader interface {
 }
 
 type Env struct {
-	r      io.Reader
+	r io.Reader
+	// Name of the env file used to look up the key of its encrypted values.
+	name   string
 	closed bool
 }
 
 func FromReader(r io.Reader) EnvReader {
-	return &Env{r: r}
+	return &Env{r: r, name: "stdin"}
 }
 
 func FromPath(filePath string) (EnvFile, error) {
 	if err := fs.FileExists(filePath); err != nil {
 		return nil, err
 	}
-	f, err := os.Open(filePath)
+	buf, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	return &Env{r: f}, nil
+	return FromBytes(filePath, buf)
 }
 
-func (e *Env) Load(overload bool) error {
-	envMap, err := e.Parse()
+// FromBytes returns the env file of the given path using its content already read,
+// so the parsed content is the one which was checked by the caller if any.
+// Encrypted contents are decrypted in memory when recognised by their header.
+func FromBytes(filePath string, buf []byte) (EnvFile, error) {
+	if strings.HasSuffix(filepath.Base(filePath), vaultFileName) {
+		return fromVault(filePath, buf)
+	}
+	if !crypt.IsEncrypted(buf) {
+		return &Env{r: bytes.NewReader(buf), name: filePath}, nil
+	}
+	decrypt, secretFn := crypt.Decrypt, DecryptionKey
+	if crypt.HasRecipients(buf) {
+		decrypt, secretFn = crypt.DecryptWith, DecryptionIdentity
+	}
+	secret, err := secretFn(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	currentEnv := map[string]bool{}
-	rawEnv := os.Environ()
-	for _, rawEnvLine := range rawEnv {
-		key := strings.Split(rawEnvLine, "=")[0]
-		currentEnv[key] = true
+	plaintext, err := decrypt(buf, secret)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot decrypt file '%s'.\n%v", filePath, err)
 	}
+	return &Env{r: bytes.NewReader(plaintext), name: filePath}, nil
+}
 
-	for key, value := range envMap {
-		if !currentEnv[key] || overload {
-			_ = os.Setenv(key, value)
-		}
+// fromVault decrypts the environment of a dotenv-vault file selected by the DOTENV_KEY variable.
+func fromVault(filePath string, buf []byte) (EnvFile, error) {
+	keys := os.Getenv(VaultKeyEnvVar)
+	if keys == "" {
+		return nil, fmt.Errorf("error: file '%s' is a dotenv-vault file, provide its key via %s", filePath, VaultKeyEnvVar)
+	}
+	plaintext, err := crypt.DecryptVault(buf, keys)
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot decrypt vault file '%s'.\n%v", filePath, err)
 	}
+	return &Env{r: bytes.NewReader(plaintext), name: filePath}, nil
+}
 
+// Load sets the variables of the env file into the process environment, overriding the existing ones if overload is set.
+// Secret references are kept as is like in Parse, so parse the variables and resolve them
+// via ResolveSecrets or a SecretResolver before loading them via Map.Load instead.
+func (e *Env) Load(overload bool) error {
+	envMap, err := e.Parse()
+	if err != nil {
+		return err
+	}
+	envMap.Load(overload)
 	return nil
 }
 
+// Parse returns the variables of the env file decrypting its encrypted values if any.
+// Secret references are kept as is, see ResolveSecrets and SecretResolver.
 func (e *Env) Parse() (Map, error) {
-	return godotenv.Parse(e.r)
+	buf, err := io.ReadAll(e.r)
+	if err != nil {
+		return nil, err
+	}
+	if crypt.HasEncryptedValues(buf) {
+		key, err := DecryptionKey(e.name)
+		if err != nil {
+			return nil, err
+		}
+		if buf, err = crypt.DecryptValues(buf, key); err != nil {
+			return nil, fmt.Errorf("error: cannot decrypt the values of file '%s'.\n%v", e.name, err)
+		}
+	}
+	return godotenv.UnmarshalBytes(buf)
 }
 
 func (e *Env) Close() error {
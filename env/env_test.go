@@ -8,7 +8,10 @@ import (
 	"strings"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/crypt"
 )
 
 func TestFromReader(t *testing.T) {
@@ -81,6 +84,157 @@ func TestFromPath(t *testing.T) {
 	}
 }
 
+func TestFromBytes(t *testing.T) {
+	t.Run("should parse the given content instead of the file one", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.env")
+		assert.NoError(t, os.WriteFile(path, []byte("KEY=modified\n"), 0644))
+
+		envFile, err := FromBytes(path, []byte("KEY=VALUE\n"))
+		assert.NoError(t, err)
+		vars, err := envFile.Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"KEY": "VALUE"}, vars)
+	})
+
+	t.Run("should decrypt encrypted content", func(t *testing.T) {
+		buf, err := crypt.Encrypt([]byte("KEY=VALUE\n"), []byte("passphrase"))
+		assert.NoError(t, err)
+		t.Setenv(KeyEnvVar, "passphrase")
+
+		envFile, err := FromBytes("test.env", buf)
+		assert.NoError(t, err)
+		vars, err := envFile.Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"KEY": "VALUE"}, vars)
+	})
+}
+
+func TestFromPath_Encrypted(t *testing.T) {
+	buf, err := crypt.Encrypt([]byte("KEY=VALUE\n"), []byte("passphrase"))
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "test.env")
+	assert.NoError(t, os.WriteFile(path, buf, 0644))
+
+	t.Run("should decrypt the file using the key variable", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "passphrase")
+		envFile, err := FromPath(path)
+		assert.NoError(t, err)
+		vars, err := envFile.Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"KEY": "VALUE"}, vars)
+		assert.NoError(t, envFile.Close())
+	})
+
+	t.Run("should return an error for a wrong key", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "wrong")
+		_, err := FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: cannot decrypt file '%s'.\n%v", path, crypt.ErrDecrypt))
+	})
+
+	t.Run("should return an error without key", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "")
+		_, err := FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: file '%s' is encrypted, provide its passphrase via ENVE_KEY", path))
+	})
+}
+
+func TestFromPath_Recipients(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	assert.NoError(t, err)
+	buf, err := crypt.EncryptFor([]byte("KEY=VALUE\n"), []string{id.Recipient().String()})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.env")
+	assert.NoError(t, os.WriteFile(path, buf, 0644))
+	identityPath := filepath.Join(dir, "identity.txt")
+	assert.NoError(t, os.WriteFile(identityPath, []byte(id.String()+"\n"), 0600))
+
+	t.Run("should decrypt the file using the identity variable", func(t *testing.T) {
+		t.Setenv(IdentityEnvVar, identityPath)
+		envFile, err := FromPath(path)
+		assert.NoError(t, err)
+		vars, err := envFile.Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"KEY": "VALUE"}, vars)
+		assert.NoError(t, envFile.Close())
+	})
+
+	t.Run("should return an error for an identity which is not a recipient", func(t *testing.T) {
+		other, err := age.GenerateX25519Identity()
+		assert.NoError(t, err)
+		otherPath := filepath.Join(dir, "other.txt")
+		assert.NoError(t, os.WriteFile(otherPath, []byte(other.String()), 0600))
+		t.Setenv(IdentityEnvVar, otherPath)
+		_, err = FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: cannot decrypt file '%s'.\n%v", path, crypt.ErrNoIdentity))
+	})
+
+	t.Run("should return an error without identity", func(t *testing.T) {
+		t.Setenv(IdentityEnvVar, "")
+		_, err := FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: file '%s' is encrypted for recipients, provide an identity file via ENVE_IDENTITY", path))
+	})
+}
+
+func TestFromPath_Vault(t *testing.T) {
+	path := filepath.Join("..", "fixtures", "vault", ".env.vault")
+
+	t.Run("should decrypt the environment of the key variable", func(t *testing.T) {
+		t.Setenv(VaultKeyEnvVar, "dotenv://:key_ddcaa26504cd70a6fef9801901c3981538563a1767c297cb8416e8a38c62fe00@dotenv.local/vault/.env.vault?environment=development")
+		envFile, err := FromPath(path)
+		assert.NoError(t, err)
+		vars, err := envFile.Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"ALPHA": "zeta"}, vars)
+		assert.NoError(t, envFile.Close())
+	})
+
+	t.Run("should return an error for a wrong key", func(t *testing.T) {
+		t.Setenv(VaultKeyEnvVar, "dotenv://:key_0000000000000000000000000000000000000000000000000000000000000000@dotenv.local/vault/.env.vault?environment=development")
+		_, err := FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: cannot decrypt vault file '%s'.\n%v", path, crypt.ErrVaultDecrypt))
+	})
+
+	t.Run("should return an error without key", func(t *testing.T) {
+		t.Setenv(VaultKeyEnvVar, "")
+		_, err := FromPath(path)
+		assert.EqualError(t, err, fmt.Sprintf("error: file '%s' is a dotenv-vault file, provide its key via DOTENV_KEY", path))
+	})
+}
+
+func TestEnv_Parse_EncryptedValues(t *testing.T) {
+	buf, err := crypt.EncryptValues([]byte("HOST=localhost\nURL=\"http://${HOST}\"\n"), []byte("passphrase"))
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "test.env")
+	assert.NoError(t, os.WriteFile(path, buf, 0644))
+
+	t.Run("should decrypt the values using the key variable", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "passphrase")
+		vars, err := FromReader(strings.NewReader(string(buf))).Parse()
+		assert.NoError(t, err)
+		assert.Equal(t, Map{"HOST": "localhost", "URL": "http://localhost"}, vars)
+	})
+
+	t.Run("should return an error for a modified file", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "passphrase")
+		assert.NoError(t, os.WriteFile(path, append([]byte("# Modified\n"), buf...), 0644))
+		envFile, err := FromPath(path)
+		assert.NoError(t, err)
+		defer envFile.Close()
+		_, err = envFile.Parse()
+		assert.EqualError(t, err, fmt.Sprintf("error: cannot decrypt the values of file '%s'.\n%v", path, crypt.ErrDecrypt))
+	})
+
+	t.Run("should return an error when the metadata comment is removed", func(t *testing.T) {
+		t.Setenv(KeyEnvVar, "passphrase")
+		lines := strings.Split(string(buf), "\n")
+		stripped := strings.Join(lines[:len(lines)-2], "\n") + "\n"
+		assert.NotContains(t, stripped, "# enve:v1")
+		_, err := FromReader(strings.NewReader(stripped)).Parse()
+		assert.ErrorContains(t, err, "the metadata of encrypted values is missing")
+	})
+}
+
 func TestEnv_Parse(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -220,3 +374,15 @@ func TestEnv_Close(t *testing.T) {
 		assert.NoError(t, err, "should not return an error for a nil reader")
 	})
 }
+
+func TestEnvironment_Slice(t *testing.T) {
+	t.Run("should return an empty slice for an empty environment", func(t *testing.T) {
+		assert.Equal(t, Slice{}, Environment{}.Slice())
+	})
+
+	t.Run("should return the variables in order", func(t *testing.T) {
+		environ := Environment{Env: []EnvironmentVar{{Name: "B", Value: "2"}, {Name: "A", Value: "a=1"}}}
+		assert.Equal(t, Slice{"B=2", "A=a=1"}, environ.Slice())
+		assert.Equal(t, environ, environ.Slice().Environ())
+	})
+}
@@ -2,6 +2,8 @@ package env
 
 import (
 	"fmt"
+	"os"
+	"strings"
 )
 
 type Map map[string]string
@@ -13,3 +15,19 @@ func (e Map) Array() []string {
 	}
 	return vars
 }
+
+// Load sets the variables into the process environment
+// replacing the existing ones only when overload is true.
+func (e Map) Load(overload bool) {
+	currentEnv := map[string]bool{}
+	for _, rawEnvLine := range os.Environ() {
+		key := strings.Split(rawEnvLine, "=")[0]
+		currentEnv[key] = true
+	}
+
+	for key, value := range e {
+		if !currentEnv[key] || overload {
+			_ = os.Setenv(key, value)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package env_test
 
 import (
+	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/joseluisq/enve/env"
 	"github.com/joseluisq/enve/helpers"
 )
@@ -37,3 +40,22 @@ func TestMap_Array(t *testing.T) {
 		})
 	}
 }
+
+func TestMap_Load(t *testing.T) {
+	t.Run("should keep existing variables", func(t *testing.T) {
+		t.Setenv("ENVE_TEST_EXISTING", "old")
+		t.Setenv("ENVE_TEST_NEW", "")
+		assert.NoError(t, os.Unsetenv("ENVE_TEST_NEW"))
+
+		env.Map{"ENVE_TEST_EXISTING": "new", "ENVE_TEST_NEW": "value"}.Load(false)
+		assert.Equal(t, "old", os.Getenv("ENVE_TEST_EXISTING"))
+		assert.Equal(t, "value", os.Getenv("ENVE_TEST_NEW"))
+	})
+
+	t.Run("should replace existing variables when overloading", func(t *testing.T) {
+		t.Setenv("ENVE_TEST_EXISTING", "old")
+
+		env.Map{"ENVE_TEST_EXISTING": "new"}.Load(true)
+		assert.Equal(t, "new", os.Getenv("ENVE_TEST_EXISTING"))
+	})
+}
@@ -0,0 +1,196 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RefPrefix is the prefix of values referencing a secret like `ref+file:///run/secrets/db`.
+const RefPrefix = "ref+"
+
+// SecretProvider resolves the secret references of a scheme.
+type SecretProvider interface {
+	// Resolve returns the secret of a reference without its `ref+<scheme>://` prefix.
+	Resolve(ref string) (string, error)
+}
+
+// SecretProviderFunc is an adapter to use ordinary functions as secret providers.
+type SecretProviderFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f SecretProviderFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// ExecProvider resolves the `ref+exec://` references using the output of their shell commands.
+// It is not registered by default since env files could run arbitrary commands,
+// so programs provide it to their resolver once the user opted in, see NewSecretResolver.
+var ExecProvider SecretProvider = SecretProviderFunc(resolveExec)
+
+var (
+	providersMu sync.RWMutex
+	// Registered secret providers by scheme.
+	providers = map[string]SecretProvider{
+		"file": SecretProviderFunc(resolveFile),
+		"env":  SecretProviderFunc(resolveEnv),
+	}
+)
+
+// RegisterProvider registers the provider resolving the references of the given scheme,
+// replacing the previous one if any. A nil provider removes the scheme.
+func RegisterProvider(scheme string, p SecretProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if p == nil {
+		delete(providers, scheme)
+		return
+	}
+	providers[scheme] = p
+}
+
+// Provider returns the provider registered for the given scheme if any.
+func Provider(scheme string) (SecretProvider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[scheme]
+	return p, ok
+}
+
+// IsSecretRef reports whether the given value is a secret reference like `ref+<scheme>://...`.
+func IsSecretRef(value string) bool {
+	rest, ok := strings.CutPrefix(value, RefPrefix)
+	if !ok {
+		return false
+	}
+	scheme, _, ok := strings.Cut(rest, "://")
+	return ok && scheme != ""
+}
+
+// ResolveSecret returns the secret of the given reference using the provider of its scheme.
+func ResolveSecret(value string) (string, error) {
+	return NewSecretResolver(nil).Resolve(value)
+}
+
+// ResolveSecrets replaces the secret references of the given variables by their secrets
+// using the registered providers. References shared by several variables are resolved once per call.
+func ResolveSecrets(vars Map) error {
+	return NewSecretResolver(nil).ResolveAll(vars)
+}
+
+// SecretResolver resolves secret references using its own providers on top of the registered ones.
+// Resolved references are cached so every reference is resolved once until the resolver is reset.
+type SecretResolver struct {
+	providers map[string]SecretProvider
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewSecretResolver returns a resolver using the given providers by scheme before the registered ones.
+// A nil provider disables the references of its scheme.
+func NewSecretResolver(providers map[string]SecretProvider) *SecretResolver {
+	return &SecretResolver{providers: providers, cache: map[string]string{}}
+}
+
+// Resolve returns the secret of the given reference using the provider of its scheme.
+func (r *SecretResolver) Resolve(value string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if secret, ok := r.cache[value]; ok {
+		return secret, nil
+	}
+	scheme, ref, _ := strings.Cut(strings.TrimPrefix(value, RefPrefix), "://")
+	p, ok := r.providers[scheme]
+	if !ok {
+		p, ok = Provider(scheme)
+	}
+	if !ok || p == nil {
+		return "", fmt.Errorf("secret provider '%s' is not supported", scheme)
+	}
+	secret, err := p.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	r.cache[value] = secret
+	return secret, nil
+}
+
+// ResolveAll replaces the secret references of the given variables by their secrets.
+func (r *SecretResolver) ResolveAll(vars Map) error {
+	keys := make([]string, 0, len(vars))
+	for k, v := range vars {
+		if IsSecretRef(v) {
+			keys = append(keys, k)
+		}
+	}
+	// NOTE: sorted so the same variable is reported first when several references fail
+	sort.Strings(keys)
+	for _, k := range keys {
+		secret, err := r.Resolve(vars[k])
+		if err != nil {
+			return fmt.Errorf("error: cannot resolve the secret reference of variable '%s'.\n%v", k, err)
+		}
+		vars[k] = secret
+	}
+	return nil
+}
+
+// Reset clears the cached secrets so the references get resolved again, e.g. once they were rotated.
+func (r *SecretResolver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = map[string]string{}
+}
+
+// resolveFile returns the content of a file without its trailing line breaks (`ref+file:///run/secrets/db`).
+func resolveFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("file path is empty")
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file '%s'.\n%v", path, err)
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
+
+// resolveExec returns the output of a shell command without its trailing line breaks (`ref+exec://pass show db`).
+func resolveExec(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("command is empty")
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	// NOTE: commands get no input so they cannot consume the env content piped via stdin
+	cmd.Stdin = nil
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("command '%s' failed (%v).\n%s", command, err, msg)
+		}
+		return "", fmt.Errorf("command '%s' failed (%v)", command, err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// resolveEnv returns the value of a variable of the process environment (`ref+env://OTHER`).
+func resolveEnv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("variable '%s' is not set", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,172 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecretRef(t *testing.T) {
+	assert.True(t, IsSecretRef("ref+file:///run/secrets/db"))
+	assert.True(t, IsSecretRef("ref+exec://pass show db"))
+	assert.False(t, IsSecretRef("ref+://value"))
+	assert.False(t, IsSecretRef("ref+file"))
+	assert.False(t, IsSecretRef("file:///run/secrets/db"))
+}
+
+func TestResolveSecrets(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "db")
+	assert.NoError(t, os.WriteFile(secretPath, []byte("s3cr3t\n"), 0600))
+	t.Setenv("ENVE_TEST_OTHER", "other value")
+	resolver := NewSecretResolver(map[string]SecretProvider{"exec": ExecProvider})
+
+	tests := []struct {
+		name        string
+		vars        Map
+		expected    Map
+		expectedErr string
+	}{
+		{
+			name:     "should resolve file references",
+			vars:     Map{"DB_PASSWORD": "ref+file://" + secretPath, "HOST": "localhost"},
+			expected: Map{"DB_PASSWORD": "s3cr3t", "HOST": "localhost"},
+		},
+		{
+			name:     "should resolve exec references",
+			vars:     Map{"TOKEN": "ref+exec://echo t0k3n"},
+			expected: Map{"TOKEN": "t0k3n"},
+		},
+		{
+			name:     "should resolve env references",
+			vars:     Map{"OTHER": "ref+env://ENVE_TEST_OTHER"},
+			expected: Map{"OTHER": "other value"},
+		},
+		{
+			name:        "should return an error naming the variable of a missing file",
+			vars:        Map{"DB_PASSWORD": "ref+file://" + secretPath + ".missing"},
+			expectedErr: "error: cannot resolve the secret reference of variable 'DB_PASSWORD'.\ncannot read file '" + secretPath + ".missing'",
+		},
+		{
+			name:     "should run commands without input",
+			vars:     Map{"INPUT": "ref+exec://cat"},
+			expected: Map{"INPUT": ""},
+		},
+		{
+			name:        "should return an error naming the variable of a failed command",
+			vars:        Map{"TOKEN": "ref+exec://exit 3"},
+			expectedErr: "error: cannot resolve the secret reference of variable 'TOKEN'.\ncommand 'exit 3' failed (exit status 3)",
+		},
+		{
+			name:        "should return an error naming the variable of an unset variable",
+			vars:        Map{"OTHER": "ref+env://ENVE_TEST_MISSING"},
+			expectedErr: "error: cannot resolve the secret reference of variable 'OTHER'.\nvariable 'ENVE_TEST_MISSING' is not set",
+		},
+		{
+			name:        "should return an error for unsupported providers",
+			vars:        Map{"KEY": "ref+vault://secret/db"},
+			expectedErr: "error: cannot resolve the secret reference of variable 'KEY'.\nsecret provider 'vault' is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolver.ResolveAll(tt.vars)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.vars)
+		})
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	calls := 0
+	RegisterProvider("test", SecretProviderFunc(func(ref string) (string, error) {
+		calls++
+		if ref == "fail" {
+			return "", errors.New("provider failed")
+		}
+		return strings.ToUpper(ref), nil
+	}))
+	defer RegisterProvider("test", nil)
+
+	t.Run("should resolve references using a registered provider once per call", func(t *testing.T) {
+		vars := Map{"A": "ref+test://secret", "B": "ref+test://secret"}
+		assert.NoError(t, ResolveSecrets(vars))
+		assert.Equal(t, Map{"A": "SECRET", "B": "SECRET"}, vars)
+		assert.Equal(t, 1, calls)
+		assert.NoError(t, ResolveSecrets(Map{"C": "ref+test://secret"}))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should not cache failed references", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			err := ResolveSecrets(Map{"A": "ref+test://fail"})
+			assert.EqualError(t, err, "error: cannot resolve the secret reference of variable 'A'.\nprovider failed")
+		}
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("should remove a provider", func(t *testing.T) {
+		RegisterProvider("test", nil)
+		_, ok := Provider("test")
+		assert.False(t, ok)
+	})
+}
+
+func TestSecretResolver(t *testing.T) {
+	calls := 0
+	RegisterProvider("test", SecretProviderFunc(func(ref string) (string, error) {
+		calls++
+		return strings.ToUpper(ref), nil
+	}))
+	defer RegisterProvider("test", nil)
+
+	t.Run("should cache the resolved references until reset", func(t *testing.T) {
+		resolver := NewSecretResolver(nil)
+		assert.NoError(t, resolver.ResolveAll(Map{"A": "ref+test://secret", "B": "ref+test://secret"}))
+		vars := Map{"C": "ref+test://secret"}
+		assert.NoError(t, resolver.ResolveAll(vars))
+		assert.Equal(t, Map{"C": "SECRET"}, vars)
+		assert.Equal(t, 1, calls)
+
+		resolver.Reset()
+		assert.NoError(t, resolver.ResolveAll(Map{"A": "ref+test://secret"}))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should use its providers before the registered ones", func(t *testing.T) {
+		resolver := NewSecretResolver(map[string]SecretProvider{
+			"test": SecretProviderFunc(func(ref string) (string, error) { return "own " + ref, nil }),
+		})
+		secret, err := resolver.Resolve("ref+test://secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "own secret", secret)
+	})
+
+	t.Run("should disable the schemes of nil providers", func(t *testing.T) {
+		resolver := NewSecretResolver(map[string]SecretProvider{"test": nil})
+		_, err := resolver.Resolve("ref+test://secret")
+		assert.EqualError(t, err, "secret provider 'test' is not supported")
+	})
+}
+
+func TestExecProvider(t *testing.T) {
+	t.Run("should not be registered by default", func(t *testing.T) {
+		_, ok := Provider("exec")
+		assert.False(t, ok)
+		err := ResolveSecrets(Map{"TOKEN": "ref+exec://echo t0k3n"})
+		assert.EqualError(t, err, "error: cannot resolve the secret reference of variable 'TOKEN'.\nsecret provider 'exec' is not supported")
+	})
+}
+
+func TestEnv_Parse_SecretRefs(t *testing.T) {
+	vars, err := FromReader(strings.NewReader("HOST=localhost\nDB_PASSWORD=ref+file:///run/secrets/db\n")).Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, Map{"HOST": "localhost", "DB_PASSWORD": "ref+file:///run/secrets/db"}, vars)
+}
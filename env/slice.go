@@ -3,6 +3,8 @@ package env
 import (
 	"encoding/json"
 	"encoding/xml"
+	"path"
+	"sort"
 	"strings"
 )
 
@@ -26,6 +28,116 @@ func (e Slice) Environ() Environment {
 	return environ
 }
 
+// Map returns the key=value pairs as a map where later entries take precedence.
+func (e Slice) Map() Map {
+	vars := Map{}
+	for _, v := range e.Environ().Env {
+		vars[v.Name] = v.Value
+	}
+	return vars
+}
+
+// Match returns the key=value pairs whose names match any of the given glob patterns.
+func (e Slice) Match(patterns ...string) (Slice, error) {
+	vars := Slice{}
+	for _, s := range e {
+		name, _, ok := strings.Cut(s, "=")
+		if !ok {
+			continue
+		}
+		for _, p := range patterns {
+			matched, err := path.Match(p, name)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				vars = append(vars, s)
+				break
+			}
+		}
+	}
+	return vars, nil
+}
+
+// Merge returns a new slice with the given variables applied on top of the current ones.
+// Existing variables are only replaced when overwrite is true
+// while new ones are appended in alphabetical order.
+func (e Slice) Merge(vars Map, overwrite bool) Slice {
+	merged := Slice{}
+	seen := map[string]bool{}
+	for _, s := range e {
+		name, _, ok := strings.Cut(s, "=")
+		if !ok {
+			continue
+		}
+		seen[name] = true
+		if val, found := vars[name]; found && overwrite {
+			s = name + "=" + val
+		}
+		merged = append(merged, s)
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		merged = append(merged, k+"="+vars[k])
+	}
+	return merged
+}
+
+// Expand replaces `${VAR}` references in the given strings using the current variables.
+// Undefined variables are replaced by an empty string while `$$` produces a literal dollar sign.
+// Any other dollar sign, like in `$VAR`, `$1` or `${A:-x}`, is kept as is.
+func (e Slice) Expand(strs []string) []string {
+	vars := e.Map()
+	expanded := make([]string, 0, len(strs))
+	for _, s := range strs {
+		expanded = append(expanded, expand(s, vars))
+	}
+	return expanded
+}
+
+// expand replaces the variable references of a single string.
+func expand(s string, vars Map) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if s[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		if s[i+1] == '{' {
+			if end := strings.IndexByte(s[i+2:], '}'); end >= 0 && isName(s[i+2:i+2+end]) {
+				b.WriteString(vars[s[i+2:i+2+end]])
+				i += end + 2
+				continue
+			}
+		}
+		b.WriteByte('$')
+	}
+	return b.String()
+}
+
+// isName reports whether the given string is a valid variable name like `[A-Za-z_][A-Za-z0-9_]*`.
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
 func (e Slice) JSON() ([]byte, error) {
 	environ := e.Environ()
 	jsonb, err := json.Marshal(environ)
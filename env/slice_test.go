@@ -212,3 +212,169 @@ func TestSlice_XML(t *testing.T) {
 		})
 	}
 }
+
+func TestSlice_Map(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Slice
+		expected Map
+	}{
+		{
+			name:     "should return an empty map for an empty slice",
+			expected: Map{},
+		},
+		{
+			name:     "should skip invalid pairs",
+			input:    Slice{"INVALID_KEY", "KEY=value", "URL=http://example.com?a=b"},
+			expected: Map{"KEY": "value", "URL": "http://example.com?a=b"},
+		},
+		{
+			name:     "should let later pairs take precedence",
+			input:    Slice{"KEY=first", "KEY=second"},
+			expected: Map{"KEY": "second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.Map(), "Map output should match")
+		})
+	}
+}
+
+func TestSlice_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       Slice
+		patterns    []string
+		expected    Slice
+		expectedErr bool
+	}{
+		{
+			name:     "should return an empty slice when no patterns are given",
+			input:    Slice{"PATH=/bin", "HOME=/root"},
+			expected: Slice{},
+		},
+		{
+			name:     "should match exact names",
+			input:    Slice{"PATH=/bin", "HOME=/root", "TERM=xterm"},
+			patterns: []string{"PATH", "TERM"},
+			expected: Slice{"PATH=/bin", "TERM=xterm"},
+		},
+		{
+			name:     "should match glob patterns",
+			input:    Slice{"LC_ALL=C", "LC_TIME=C", "LANG=C", "INVALID"},
+			patterns: []string{"LC_*"},
+			expected: Slice{"LC_ALL=C", "LC_TIME=C"},
+		},
+		{
+			name:        "should return an error for a malformed pattern",
+			input:       Slice{"PATH=/bin"},
+			patterns:    []string{"[PATH"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.input.Match(tt.patterns...)
+			if tt.expectedErr {
+				assert.Error(t, err, "Expected an error but got none")
+			} else {
+				assert.NoError(t, err, "Did not expect an error but got one")
+				assert.Equal(t, tt.expected, actual, "Match output should match")
+			}
+		})
+	}
+}
+
+func TestSlice_Merge(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     Slice
+		vars      Map
+		overwrite bool
+		expected  Slice
+	}{
+		{
+			name:     "should return an empty slice when both are empty",
+			expected: Slice{},
+		},
+		{
+			name:     "should append new variables in alphabetical order",
+			input:    Slice{"PATH=/bin"},
+			vars:     Map{"PORT": "8080", "HOST": "localhost"},
+			expected: Slice{"PATH=/bin", "HOST=localhost", "PORT=8080"},
+		},
+		{
+			name:     "should not replace existing variables without overwrite",
+			input:    Slice{"HOST=0.0.0.0"},
+			vars:     Map{"HOST": "localhost"},
+			expected: Slice{"HOST=0.0.0.0"},
+		},
+		{
+			name:      "should replace existing variables with overwrite",
+			input:     Slice{"HOST=0.0.0.0", "PATH=/bin"},
+			vars:      Map{"HOST": "localhost"},
+			overwrite: true,
+			expected:  Slice{"HOST=localhost", "PATH=/bin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.Merge(tt.vars, tt.overwrite), "Merge output should match")
+		})
+	}
+}
+
+func TestSlice_Expand(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Slice
+		strs     []string
+		expected []string
+	}{
+		{
+			name:     "should return an empty slice for no strings",
+			input:    Slice{"HOST=localhost"},
+			expected: []string{},
+		},
+		{
+			name:     "should expand braced references",
+			input:    Slice{"HOST=localhost", "PORT=8080"},
+			strs:     []string{"${HOST}:${PORT}", "plain"},
+			expected: []string{"localhost:8080", "plain"},
+		},
+		{
+			name:     "should replace undefined variables with an empty string",
+			input:    Slice{"HOST=localhost"},
+			strs:     []string{"${HOST}${UNDEFINED}"},
+			expected: []string{"localhost"},
+		},
+		{
+			name:     "should produce a literal dollar sign when escaped",
+			input:    Slice{"HOST=localhost"},
+			strs:     []string{"$${HOST}", "cost: 5$$"},
+			expected: []string{"${HOST}", "cost: 5$"},
+		},
+		{
+			name:     "should keep dollar signs not followed by a braced variable name",
+			input:    Slice{"HOST=localhost"},
+			strs:     []string{"$HOST", "p@$$w0rd$HOST", "$1", "cost $5", "$@ $* $? $- $! $#", "$", "end$", "${HOST"},
+			expected: []string{"$HOST", "p@$w0rd$HOST", "$1", "cost $5", "$@ $* $? $- $! $#", "$", "end$", "${HOST"},
+		},
+		{
+			name:     "should keep braced references with invalid names",
+			input:    Slice{"A=1", "A B=2"},
+			strs:     []string{"${A B}", "${A:-x}", "${}", "${1A}", "${A_1}"},
+			expected: []string{"${A B}", "${A:-x}", "${}", "${1A}", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.input.Expand(tt.strs), "Expand output should match")
+		})
+	}
+}
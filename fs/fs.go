@@ -4,6 +4,7 @@ package fs
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 func FileExists(filePath string) error {
@@ -33,3 +34,35 @@ func DirExists(dirPath string) error {
 		return nil
 	}
 }
+
+// WriteFileAtomic writes data to a file via a temporary file renamed over it,
+// so readers never see a partially written file. The mode of an existing file is preserved.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("error: cannot write file '%s'.\n%v", filePath, err)
+	}
+	return nil
+}
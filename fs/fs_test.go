@@ -3,6 +3,7 @@ package fs
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -69,3 +70,44 @@ func TestDirExists(t *testing.T) {
 		assert.NoError(t, err, "should not return an error for an existing directory")
 	})
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("should create a new file with the given mode", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), ".env")
+		err := WriteFileAtomic(filePath, []byte("A=1\n"), 0600)
+		assert.NoError(t, err, "should not return an error")
+
+		data, err := os.ReadFile(filePath)
+		assert.NoError(t, err, "should read the file")
+		assert.Equal(t, "A=1\n", string(data), "file content should match")
+	})
+
+	t.Run("should replace an existing file keeping its mode", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, ".env")
+		assert.NoError(t, os.WriteFile(filePath, []byte("A=1\n"), 0640))
+		assert.NoError(t, os.Chmod(filePath, 0640))
+
+		err := WriteFileAtomic(filePath, []byte("A=2\n"), 0600)
+		assert.NoError(t, err, "should not return an error")
+
+		data, err := os.ReadFile(filePath)
+		assert.NoError(t, err, "should read the file")
+		assert.Equal(t, "A=2\n", string(data), "file content should match")
+
+		info, err := os.Stat(filePath)
+		assert.NoError(t, err, "should stat the file")
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "file mode should be preserved")
+		}
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err, "should read the directory")
+		assert.Len(t, entries, 1, "temporary files should be removed")
+	})
+
+	t.Run("should return an error for a non-existent directory", func(t *testing.T) {
+		err := WriteFileAtomic(filepath.Join(t.TempDir(), "missing", ".env"), []byte("A=1\n"), 0600)
+		assert.ErrorContains(t, err, "error: cannot write file", "error message should match")
+	})
+}
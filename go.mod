@@ -3,13 +3,17 @@ module github.com/joseluisq/enve
 go 1.23.0
 
 require (
+	filippo.io/age v1.2.1
 	github.com/joho/godotenv v1.5.1
 	github.com/joseluisq/cline v1.0.0
 	github.com/stretchr/testify v1.11.1
+	golang.org/x/crypto v0.31.0
+	golang.org/x/term v0.27.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,285 @@
+// Package schema provides validation of environment variables against a JSON or dotenv-style schema file.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joseluisq/enve/dotenv"
+	"github.com/joseluisq/enve/env"
+	"github.com/joseluisq/enve/fs"
+)
+
+// Supported variable types.
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeBool     = "bool"
+	TypeURL      = "url"
+	TypePort     = "port"
+	TypeDuration = "duration"
+	TypeEnum     = "enum"
+	TypeRegex    = "regex"
+)
+
+// Rule describes the constraints of a single variable.
+type Rule struct {
+	// Type of the value, string by default.
+	Type string `json:"type"`
+	// Whether the variable must be set to a non-empty value.
+	Required bool `json:"required"`
+	// Value applied when the variable is not set.
+	Default *string `json:"default"`
+	// Allowed values of an enum.
+	Values []string `json:"values"`
+	// Pattern which must match the whole value of a regex.
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// Schema maps variable names to their rules.
+type Schema map[string]*Rule
+
+// Violation describes a variable not matching its rule.
+type Violation struct {
+	Name    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Name + ": " + v.Message
+}
+
+// FromPath parses the schema of the given file.
+// Contents starting with '{' are parsed as JSON, otherwise as a dotenv-style schema.
+func FromPath(filePath string) (Schema, error) {
+	if err := fs.FileExists(filePath); err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	parse := ParseDotenv
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte("{")) {
+		parse = Parse
+	}
+	s, err := parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("error: cannot parse schema file '%s'.\n%v", filePath, err)
+	}
+	return s, nil
+}
+
+// Parse reads a JSON schema making sure all its rules are valid.
+func Parse(r io.Reader) (Schema, error) {
+	var s Schema
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseDotenv reads a dotenv-style schema like a `.env.example` file making sure all its rules are valid.
+// Every variable gets a rule whose default is its value if not empty. Rules are annotated by
+// the `@required`, `@type=<type>`, `@values=<a,b,...>` and `@pattern=<regex>` tags found
+// in the comment lines right above the variable or in its trailing comment.
+func ParseDotenv(r io.Reader) (Schema, error) {
+	f, err := dotenv.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Err(); err != nil {
+		return nil, err
+	}
+	s := Schema{}
+	var tags []string
+	for _, n := range f.Nodes {
+		switch n.Kind {
+		case dotenv.Blank:
+			tags = nil
+		case dotenv.Comment:
+			tags = append(tags, strings.Fields(strings.TrimPrefix(n.Comment, "#"))...)
+		case dotenv.Variable:
+			tags = append(tags, strings.Fields(strings.TrimPrefix(n.Comment, "#"))...)
+			rule := &Rule{}
+			if n.Value != "" {
+				value := n.Value
+				rule.Default = &value
+			}
+			if err := rule.annotate(tags); err != nil {
+				return nil, fmt.Errorf("invalid rule for '%s': %v", n.Key, err)
+			}
+			s[n.Key] = rule
+			tags = nil
+		}
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// annotate sets the rule properties from the given comment words, ignoring the ones which are not tags.
+func (r *Rule) annotate(words []string) error {
+	for _, w := range words {
+		if !strings.HasPrefix(w, "@") {
+			continue
+		}
+		tag, value, _ := strings.Cut(w, "=")
+		switch tag {
+		case "@required":
+			r.Required = true
+		case "@type":
+			r.Type = value
+		case "@values":
+			r.Values = strings.Split(value, ",")
+		case "@pattern":
+			r.Pattern = value
+		default:
+			return fmt.Errorf("tag '%s' is not supported", tag)
+		}
+	}
+	return nil
+}
+
+// compile checks all the rules of the schema.
+func (s Schema) compile() error {
+	for _, name := range s.names() {
+		rule := s[name]
+		if rule == nil {
+			rule = &Rule{}
+			s[name] = rule
+		}
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("invalid rule for '%s': %v", name, err)
+		}
+	}
+	return nil
+}
+
+// compile checks the rule definition preparing its pattern if any.
+func (r *Rule) compile() error {
+	if r.Type == "" {
+		r.Type = TypeString
+	}
+	switch r.Type {
+	case TypeString, TypeInt, TypeBool, TypeURL, TypePort, TypeDuration:
+	case TypeEnum:
+		if len(r.Values) == 0 {
+			return fmt.Errorf("enum type requires a list of values")
+		}
+	case TypeRegex:
+		re, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s'", r.Pattern)
+		}
+		r.re = re
+	default:
+		return fmt.Errorf("type '%s' is not supported", r.Type)
+	}
+	if r.Default != nil {
+		if msg := r.check(*r.Default); msg != "" {
+			return fmt.Errorf("default %s", msg)
+		}
+	}
+	return nil
+}
+
+// check returns the reason why the given value does not match the rule type or empty if it does.
+func (r *Rule) check(value string) string {
+	switch r.Type {
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Sprintf("value '%s' is not a valid integer", value)
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("value '%s' is not a valid boolean", value)
+		}
+	case TypeURL:
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Sprintf("value '%s' is not a valid URL", value)
+		}
+	case TypePort:
+		if p, err := strconv.Atoi(value); err != nil || p < 1 || p > 65535 {
+			return fmt.Sprintf("value '%s' is not a valid port", value)
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Sprintf("value '%s' is not a valid duration", value)
+		}
+	case TypeEnum:
+		for _, v := range r.Values {
+			if v == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value '%s' is not one of %s", value, strings.Join(r.Values, ", "))
+	case TypeRegex:
+		if r.re != nil && !r.re.MatchString(value) {
+			return fmt.Sprintf("value '%s' does not match the pattern '%s'", value, r.Pattern)
+		}
+	}
+	return ""
+}
+
+// names returns the variable names of the schema in alphabetical order.
+func (s Schema) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Defaults returns the default values of the variables which are not set.
+func (s Schema) Defaults(vars env.Map) env.Map {
+	defaults := env.Map{}
+	for name, rule := range s {
+		if _, ok := vars[name]; !ok && rule.Default != nil {
+			defaults[name] = *rule.Default
+		}
+	}
+	return defaults
+}
+
+// Validate returns all the variables not matching their rules in alphabetical order.
+// Empty values are only reported for required variables.
+func (s Schema) Validate(vars env.Map) []Violation {
+	var violations []Violation
+	for _, name := range s.names() {
+		rule := s[name]
+		value, ok := vars[name]
+		if !ok || value == "" {
+			if rule.Required {
+				msg := "required variable is not set"
+				if ok {
+					msg = "required variable is empty"
+				}
+				violations = append(violations, Violation{Name: name, Message: msg})
+			}
+			continue
+		}
+		if msg := rule.check(value); msg != "" {
+			violations = append(violations, Violation{Name: name, Message: msg})
+		}
+	}
+	return violations
+}
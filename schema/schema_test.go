@@ -0,0 +1,230 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joseluisq/enve/env"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name:  "should parse a valid schema",
+			input: `{"PORT": {"type": "port", "default": "80"}, "NAME": {}, "LEVEL": {"type": "enum", "values": ["a"]}}`,
+		},
+		{
+			name:        "should return an error for an unsupported type",
+			input:       `{"PORT": {"type": "float"}}`,
+			expectedErr: "invalid rule for 'PORT': type 'float' is not supported",
+		},
+		{
+			name:        "should return an error for an enum without values",
+			input:       `{"LEVEL": {"type": "enum"}}`,
+			expectedErr: "invalid rule for 'LEVEL': enum type requires a list of values",
+		},
+		{
+			name:        "should return an error for an invalid pattern",
+			input:       `{"NAME": {"type": "regex", "pattern": "[a-"}}`,
+			expectedErr: "invalid rule for 'NAME': invalid pattern '[a-'",
+		},
+		{
+			name:        "should return an error for an invalid default",
+			input:       `{"PORT": {"type": "port", "default": "http"}}`,
+			expectedErr: "invalid rule for 'PORT': default value 'http' is not a valid port",
+		},
+		{
+			name:        "should return an error for an unknown field",
+			input:       `{"PORT": {"type": "port", "requried": true}}`,
+			expectedErr: `json: unknown field "requried"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(strings.NewReader(tt.input))
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, s)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := Parse(strings.NewReader(`{
+		"API_URL": {"type": "url", "required": true},
+		"DEBUG": {"type": "bool"},
+		"LEVEL": {"type": "enum", "values": ["debug", "info"]},
+		"NAME": {"type": "regex", "pattern": "[a-z]+"},
+		"PORT": {"type": "port"},
+		"TIMEOUT": {"type": "duration"},
+		"WORKERS": {"type": "int", "required": true}
+	}`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		vars     env.Map
+		expected []Violation
+	}{
+		{
+			name: "should return no violations for valid values",
+			vars: env.Map{
+				"API_URL": "https://example.com/api",
+				"DEBUG":   "false",
+				"LEVEL":   "info",
+				"NAME":    "enve",
+				"PORT":    "8080",
+				"TIMEOUT": "1m30s",
+				"WORKERS": "-1",
+			},
+		},
+		{
+			name: "should skip empty values of optional variables",
+			vars: env.Map{"API_URL": "http://localhost", "WORKERS": "1", "PORT": ""},
+		},
+		{
+			name: "should return all violations",
+			vars: env.Map{
+				"API_URL": "localhost",
+				"DEBUG":   "yes",
+				"LEVEL":   "trace",
+				"NAME":    "enve1",
+				"PORT":    "70000",
+				"TIMEOUT": "10",
+				"WORKERS": "",
+			},
+			expected: []Violation{
+				{Name: "API_URL", Message: "value 'localhost' is not a valid URL"},
+				{Name: "DEBUG", Message: "value 'yes' is not a valid boolean"},
+				{Name: "LEVEL", Message: "value 'trace' is not one of debug, info"},
+				{Name: "NAME", Message: "value 'enve1' does not match the pattern '[a-z]+'"},
+				{Name: "PORT", Message: "value '70000' is not a valid port"},
+				{Name: "TIMEOUT", Message: "value '10' is not a valid duration"},
+				{Name: "WORKERS", Message: "required variable is empty"},
+			},
+		},
+		{
+			name: "should return the required variables not set",
+			vars: env.Map{"WORKERS": "x"},
+			expected: []Violation{
+				{Name: "API_URL", Message: "required variable is not set"},
+				{Name: "WORKERS", Message: "value 'x' is not a valid integer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.Validate(tt.vars))
+		})
+	}
+}
+
+func TestSchema_Defaults(t *testing.T) {
+	s, err := Parse(strings.NewReader(`{
+		"PORT": {"type": "port", "default": "80"},
+		"HOST": {"default": "localhost"},
+		"NAME": {}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, env.Map{"HOST": "localhost"}, s.Defaults(env.Map{"PORT": ""}))
+	assert.Equal(t, env.Map{"HOST": "localhost", "PORT": "80"}, s.Defaults(env.Map{}))
+}
+
+func TestParseDotenv(t *testing.T) {
+	port, host, level := "8080", "localhost", "info"
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    Schema
+		expectedErr string
+	}{
+		{
+			name: "should parse the tags of the comments above or trailing the variables",
+			input: "# The server port\n# @type=port\nPORT=8080\n\n" +
+				"# @required @type=url\nAPI_URL=\n" +
+				"LEVEL=info # @type=enum @values=debug,info\n" +
+				"# @type=int\n\nHOST=localhost\n",
+			expected: Schema{
+				"PORT":    {Type: TypePort, Default: &port},
+				"API_URL": {Type: TypeURL, Required: true},
+				"LEVEL":   {Type: TypeEnum, Default: &level, Values: []string{"debug", "info"}},
+				"HOST":    {Type: TypeString, Default: &host},
+			},
+		},
+		{
+			name:     "should parse an example file without tags",
+			input:    "export HOST='localhost'\nTOKEN=\n",
+			expected: Schema{"HOST": {Type: TypeString, Default: &host}, "TOKEN": {Type: TypeString}},
+		},
+		{
+			name:        "should return an error for an unsupported tag",
+			input:       "# @requried\nPORT=\n",
+			expectedErr: "invalid rule for 'PORT': tag '@requried' is not supported",
+		},
+		{
+			name:        "should return an error for an invalid default",
+			input:       "# @type=port\nPORT=http\n",
+			expectedErr: "invalid rule for 'PORT': default value 'http' is not a valid port",
+		},
+		{
+			name:        "should return an error for an invalid statement",
+			input:       "PORT='8080\n",
+			expectedErr: "line 1:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseDotenv(strings.NewReader(tt.input))
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, s)
+			}
+		})
+	}
+
+	t.Run("should validate the pattern of a regex", func(t *testing.T) {
+		s, err := ParseDotenv(strings.NewReader("# @type=regex @pattern=v[0-9]+\nRELEASE=v1\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []Violation{{Name: "RELEASE", Message: "value 'x' does not match the pattern 'v[0-9]+'"}}, s.Validate(env.Map{"RELEASE": "x"}))
+	})
+}
+
+func TestFromPath(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, ".env.schema.json")
+	dotenvPath := filepath.Join(dir, ".env.example")
+	assert.NoError(t, os.WriteFile(jsonPath, []byte(`  {"PORT": {"type": "port"}}`), 0644))
+	assert.NoError(t, os.WriteFile(dotenvPath, []byte("# @type=port\nPORT=\n"), 0644))
+
+	t.Run("should parse both JSON and dotenv-style schemas", func(t *testing.T) {
+		for _, filePath := range []string{jsonPath, dotenvPath} {
+			s, err := FromPath(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, Schema{"PORT": {Type: TypePort}}, s)
+		}
+	})
+
+	t.Run("should return an error naming the invalid schema file", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(dotenvPath, []byte("# @type=float\nPORT=\n"), 0644))
+		_, err := FromPath(dotenvPath)
+		assert.EqualError(t, err, "error: cannot parse schema file '"+dotenvPath+"'.\ninvalid rule for 'PORT': type 'float' is not supported")
+	})
+}
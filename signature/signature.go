@@ -0,0 +1,128 @@
+// Package signature provides detached Ed25519 signatures of env files
+// computed over their contents with normalised line endings.
+package signature
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joseluisq/enve/fs"
+)
+
+// Ext is the extension appended to env files to get the path of their signature.
+const Ext = ".sig"
+
+// ErrMismatch is returned when a signature does not match the contents of a file.
+var ErrMismatch = errors.New("signature does not match the file contents")
+
+// Path returns the path of the signature of the given env file.
+func Path(filePath string) string {
+	return filePath + Ext
+}
+
+// Canonical returns the given env file contents in the form which gets signed,
+// so signatures do not depend on the line endings of the platform.
+// Only CRLF line endings are converted to LF, the way they are when parsing the file,
+// so any other change of the contents breaks the signature.
+func Canonical(buf []byte) []byte {
+	return bytes.ReplaceAll(buf, []byte("\r\n"), []byte("\n"))
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 Ed25519 private key
+// like the ones generated via `openssl genpkey -algorithm ed25519`.
+func ParsePrivateKey(buf []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("private key is not a PEM encoded PKCS #8 key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("private key is invalid.\n%v", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an Ed25519 key")
+	}
+	return edKey, nil
+}
+
+// ParsePublicKey parses a PEM encoded PKIX Ed25519 public key
+// like the ones generated via `openssl pkey -pubout`.
+func ParsePublicKey(buf []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(buf)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("public key is not a PEM encoded PKIX key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key is invalid.\n%v", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an Ed25519 key")
+	}
+	return edKey, nil
+}
+
+// Sign returns the base64 encoded signature of the given env file contents.
+func Sign(buf []byte, key ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(key, Canonical(buf))
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// Verify checks the base64 encoded signature of the given env file contents.
+func Verify(buf, sig []byte, key ed25519.PublicKey) error {
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil || len(decoded) != ed25519.SignatureSize {
+		return errors.New("signature is invalid")
+	}
+	if !ed25519.Verify(key, Canonical(buf), decoded) {
+		return ErrMismatch
+	}
+	return nil
+}
+
+// SignFile writes the detached signature of the given env file and returns its path.
+func SignFile(filePath string, key ed25519.PrivateKey) (string, error) {
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	sigPath := Path(filePath)
+	if err := fs.WriteFileAtomic(sigPath, Sign(buf, key), 0644); err != nil {
+		return "", err
+	}
+	return sigPath, nil
+}
+
+// VerifyFile checks the detached signature of the given env file.
+func VerifyFile(filePath string, key ed25519.PublicKey) error {
+	buf, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	return VerifyFileContent(filePath, buf, key)
+}
+
+// VerifyFileContent checks the detached signature of the given env file against its content already read,
+// so the verified content is the one which gets loaded afterwards.
+func VerifyFileContent(filePath string, buf []byte, key ed25519.PublicKey) error {
+	sigPath := Path(filePath)
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("error: env file '%s' is not signed, signature file '%s' was not found", filePath, sigPath)
+		}
+		return fmt.Errorf("error: cannot read signature file '%s'.\n%v", sigPath, err)
+	}
+	if err := Verify(buf, sig, key); err != nil {
+		return fmt.Errorf("error: cannot verify the signature of env file '%s'.\n%v", filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "should keep canonical contents",
+			input:    "# App\nHOST=localhost\n",
+			expected: "# App\nHOST=localhost\n",
+		},
+		{
+			name:     "should convert CRLF line endings",
+			input:    "# App\r\nHOST=localhost\r\nPORT=80\r\n",
+			expected: "# App\nHOST=localhost\nPORT=80\n",
+		},
+		{
+			name:     "should keep lone carriage returns",
+			input:    "HOST=\"local\rhost\"\n",
+			expected: "HOST=\"local\rhost\"\n",
+		},
+		{
+			name:     "should keep whitespace and blank lines",
+			input:    "\xef\xbb\xbfKEY=\"multi \t\nline \"\n\nPORT=80\n\n",
+			expected: "\xef\xbb\xbfKEY=\"multi \t\nline \"\n\nPORT=80\n\n",
+		},
+		{
+			name:     "should not add a final newline",
+			input:    "HOST=localhost",
+			expected: "HOST=localhost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(Canonical([]byte(tt.input))))
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	sig := Sign([]byte("HOST=localhost\n"), key)
+
+	assert.NoError(t, Verify([]byte("HOST=localhost\n"), sig, pub))
+	assert.NoError(t, Verify([]byte("HOST=localhost\r\n"), sig, pub))
+	assert.ErrorIs(t, Verify([]byte("HOST=localhost  \n"), sig, pub), ErrMismatch)
+	assert.ErrorIs(t, Verify([]byte("HOST=127.0.0.1\n"), sig, pub), ErrMismatch)
+	assert.ErrorIs(t, Verify([]byte("HOST=localhost\n"), sig, otherPub), ErrMismatch)
+	assert.EqualError(t, Verify([]byte("HOST=localhost\n"), []byte("invalid"), pub), "signature is invalid")
+}
+
+func TestSignFile(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	filePath := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(filePath, []byte("HOST=localhost\n"), 0644))
+
+	err = VerifyFile(filePath, pub)
+	assert.EqualError(t, err, "error: env file '"+filePath+"' is not signed, signature file '"+filePath+".sig' was not found")
+
+	sigPath, err := SignFile(filePath, key)
+	assert.NoError(t, err)
+	assert.Equal(t, filePath+".sig", sigPath)
+	assert.NoError(t, VerifyFile(filePath, pub))
+	assert.NoError(t, VerifyFileContent(filePath, []byte("HOST=localhost\n"), pub))
+	err = VerifyFileContent(filePath, []byte("HOST=example.com\n"), pub)
+	assert.EqualError(t, err, "error: cannot verify the signature of env file '"+filePath+"'.\n"+ErrMismatch.Error())
+
+	assert.NoError(t, os.WriteFile(filePath, []byte("HOST=example.com\n"), 0644))
+	err = VerifyFile(filePath, pub)
+	assert.EqualError(t, err, "error: cannot verify the signature of env file '"+filePath+"'.\n"+ErrMismatch.Error())
+}
+
+func TestParseKeys(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	parsedKey, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsedKey)
+
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	parsedPub, err := ParsePublicKey(pubPEM)
+	assert.NoError(t, err)
+	assert.Equal(t, pub, parsedPub)
+
+	_, err = ParsePrivateKey(pubPEM)
+	assert.EqualError(t, err, "private key is not a PEM encoded PKCS #8 key")
+	_, err = ParsePublicKey([]byte("invalid"))
+	assert.EqualError(t, err, "public key is not a PEM encoded PKIX key")
+}
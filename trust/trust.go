@@ -0,0 +1,179 @@
+// Package trust provides a user-level store of env files trusted along with their content hashes.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/joseluisq/enve/fs"
+)
+
+// Status describes whether an env file can be loaded.
+type Status int
+
+const (
+	// Untrusted means the file was never allowed or was denied.
+	Untrusted Status = iota
+	// Trusted means the file was allowed and its content did not change since then.
+	Trusted
+	// Modified means the file was allowed but its content changed since then.
+	Modified
+)
+
+// storeFile is the file name of the store database.
+const storeFile = "trust.json"
+
+// Store keeps the trusted file paths along with their content hashes.
+type Store struct {
+	dir string
+}
+
+// New creates a store located in the given directory.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Default creates a store located in `$XDG_DATA_HOME/enve` or `~/.local/share/enve` as a fallback.
+func Default() (*Store, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error: cannot determine the trust store directory.\n%v", err)
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return New(filepath.Join(dataDir, "enve")), nil
+}
+
+// Dir returns the directory of the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Hash returns the SHA-256 hash of the given file content.
+func Hash(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("error: cannot access file '%s'.\n%v", filePath, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error: cannot read file '%s'.\n%v", filePath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashContent returns the SHA-256 hash of the given content.
+func HashContent(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// Allow trusts the given file with its current content and returns its absolute path.
+func (s *Store) Allow(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	hash, err := Hash(absPath)
+	if err != nil {
+		return "", err
+	}
+	entries, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	entries[absPath] = hash
+	return absPath, s.write(entries)
+}
+
+// Deny removes the trust of the given file and returns its absolute path.
+func (s *Store) Deny(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	entries, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := entries[absPath]; !ok {
+		return absPath, nil
+	}
+	delete(entries, absPath)
+	return absPath, s.write(entries)
+}
+
+// Check returns the trust status of the given file.
+func (s *Store) Check(filePath string) (Status, error) {
+	return s.check(filePath, func(absPath string) (string, error) {
+		return Hash(absPath)
+	})
+}
+
+// CheckContent returns the trust status of the given file using its content already read,
+// so the trusted content is the one which gets loaded by the caller.
+func (s *Store) CheckContent(filePath string, buf []byte) (Status, error) {
+	return s.check(filePath, func(string) (string, error) {
+		return HashContent(buf), nil
+	})
+}
+
+// check compares the trusted hash of the given file with the one computed by the hash function.
+func (s *Store) check(filePath string, hash func(absPath string) (string, error)) (Status, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return Untrusted, err
+	}
+	entries, err := s.read()
+	if err != nil {
+		return Untrusted, err
+	}
+	trusted, ok := entries[absPath]
+	if !ok {
+		return Untrusted, nil
+	}
+	actual, err := hash(absPath)
+	if err != nil {
+		return Untrusted, err
+	}
+	if actual != trusted {
+		return Modified, nil
+	}
+	return Trusted, nil
+}
+
+// read returns the entries of the store, an empty set is returned if the store does not exist yet.
+func (s *Store) read() (map[string]string, error) {
+	entries := map[string]string{}
+	buf, err := os.ReadFile(filepath.Join(s.dir, storeFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("error: cannot read the trust store.\n%v", err)
+	}
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		return nil, fmt.Errorf("error: cannot parse the trust store.\n%v", err)
+	}
+	return entries, nil
+}
+
+// write saves the entries of the store atomically.
+func (s *Store) write(entries map[string]string) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("error: cannot create the trust store directory.\n%v", err)
+	}
+	buf, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fs.WriteFileAtomic(filepath.Join(s.dir, storeFile), append(buf, '\n'), 0600)
+}
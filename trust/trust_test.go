@@ -0,0 +1,98 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	t.Run("should use the XDG data directory", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "/tmp/data")
+		store, err := Default()
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join("/tmp/data", "enve"), store.Dir())
+	})
+
+	t.Run("should fallback to the local share directory", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "")
+		home, err := os.UserHomeDir()
+		assert.NoError(t, err)
+		store, err := Default()
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, ".local", "share", "enve"), store.Dir())
+	})
+}
+
+func TestHash(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(filePath, []byte("FOO=bar\n"), 0644))
+
+	hash, err := Hash(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "82958eef9224c9a65d7dbe7ae6374c2d0519c61351eb10076d9339782d332cfa", hash)
+
+	_, err = Hash(filepath.Join(t.TempDir(), "missing.env"))
+	assert.ErrorContains(t, err, "error: cannot access file")
+
+	assert.Equal(t, hash, HashContent([]byte("FOO=bar\n")))
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := New(filepath.Join(dir, "data"))
+	filePath := filepath.Join(dir, ".env")
+	assert.NoError(t, os.WriteFile(filePath, []byte("FOO=bar\n"), 0644))
+
+	t.Run("should report an unknown file as untrusted", func(t *testing.T) {
+		status, err := store.Check(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, Untrusted, status)
+	})
+
+	t.Run("should report an allowed file as trusted", func(t *testing.T) {
+		absPath, err := store.Allow(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, filePath, absPath)
+
+		status, err := store.Check(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, Trusted, status)
+	})
+
+	t.Run("should report a file changed since allowed as modified", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filePath, []byte("FOO=baz\n"), 0644))
+		status, err := store.Check(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, Modified, status)
+	})
+
+	t.Run("should check the given content instead of the file one", func(t *testing.T) {
+		status, err := store.CheckContent(filePath, []byte("FOO=bar\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, Trusted, status)
+
+		status, err = store.CheckContent(filePath, []byte("FOO=baz\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, Modified, status)
+	})
+
+	t.Run("should report a denied file as untrusted", func(t *testing.T) {
+		_, err := store.Allow(filePath)
+		assert.NoError(t, err)
+		_, err = store.Deny(filePath)
+		assert.NoError(t, err)
+
+		status, err := store.Check(filePath)
+		assert.NoError(t, err)
+		assert.Equal(t, Untrusted, status)
+	})
+
+	t.Run("should return an error for an invalid store", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(store.Dir(), storeFile), []byte("{"), 0600))
+		_, err := store.Check(filePath)
+		assert.ErrorContains(t, err, "error: cannot parse the trust store.")
+	})
+}